@@ -0,0 +1,105 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+// sij returns Sij at every frequency, converting from Param if needed
+func (n *Network) sij(i, j int) []complex128 {
+	data := n.Data
+	if n.Param != S {
+		data = n.S()
+	}
+
+	s := make([]complex128, n.Freq.NPts)
+	for k := range s {
+		s[k] = data[k].Get(i, j)
+	}
+	return s
+}
+
+func (n *Network) Smag(i, j int) *mat.Vector {
+	v := vf(n.Freq.NPts)
+	for k, val := range n.sij(i, j) {
+		v.Set(k, cmplx.Abs(val))
+	}
+	return v
+}
+
+func (n *Network) Sdb(i, j int) *mat.Vector {
+	v := vf(n.Freq.NPts)
+	for k, val := range n.sij(i, j) {
+		v.Set(k, 20*math.Log10(cmplx.Abs(val)))
+	}
+	return v
+}
+
+// Sphase returns the phase of Sij in degrees, optionally unwrapped
+func (n *Network) Sphase(i, j int, unwrapped bool) *mat.Vector {
+	ang := make([]float64, n.Freq.NPts)
+	for k, val := range n.sij(i, j) {
+		ang[k] = cmplx.Phase(val)
+	}
+	if unwrapped {
+		ang = unwrap(ang)
+	}
+
+	v := vf(n.Freq.NPts)
+	for k, val := range ang {
+		v.Set(k, val*180/math.Pi)
+	}
+	return v
+}
+
+// GroupDelay returns -d(phase)/d(omega) of Sij in seconds, using central
+// differences of the unwrapped phase
+func (n *Network) GroupDelay(i, j int) *mat.Vector {
+	if n.Freq.NPts < 2 {
+		panic("group delay requires at least 2 frequency points")
+	}
+
+	ang := make([]float64, n.Freq.NPts)
+	for k, val := range n.sij(i, j) {
+		ang[k] = cmplx.Phase(val)
+	}
+	ang = unwrap(ang)
+	w := n.Freq.W.Data
+
+	v := vf(n.Freq.NPts)
+	for k := range ang {
+		lo, hi := maxint(k-1, 0), minint(k+1, n.Freq.NPts-1)
+		v.Set(k, -(ang[hi]-ang[lo])/(w[hi]-w[lo]))
+	}
+	return v
+}
+
+func (n *Network) VSWR(i int) *mat.Vector {
+	v := vf(n.Freq.NPts)
+	for k, val := range n.sij(i, i) {
+		v.Set(k, (1+cmplx.Abs(val))/(1-cmplx.Abs(val)))
+	}
+	return v
+}
+
+// ReturnLoss returns the return loss at port i in dB, positive for a passive
+// port
+func (n *Network) ReturnLoss(i int) *mat.Vector {
+	v := n.Sdb(i, i)
+	for k := range v.Data {
+		v.Data[k] = -v.Data[k]
+	}
+	return v
+}
+
+// InsertionLoss returns the insertion loss from port j to port i in dB,
+// positive for a lossy path
+func (n *Network) InsertionLoss(i, j int) *mat.Vector {
+	v := n.Sdb(i, j)
+	for k := range v.Data {
+		v.Data[k] = -v.Data[k]
+	}
+	return v
+}
@@ -0,0 +1,39 @@
+package gorf
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAccessors(t *testing.T) {
+	tau := 0.3e-9
+	net := delayNetwork(tau)
+	for k := range net.Data {
+		net.Data[k].Set(0, 0, 0.5)
+		net.Data[k].Set(1, 0, net.Data[k].Get(1, 0)*0.1)
+	}
+
+	sdb, smag, gd := net.Sdb(1, 0), net.Smag(1, 0), net.GroupDelay(1, 0)
+	phase, wrapped := net.Sphase(1, 0, true), net.Sphase(1, 0, false)
+	vswr, rl, il := net.VSWR(0), net.ReturnLoss(0), net.InsertionLoss(1, 0)
+	for k := 0; k < net.Freq.NPts; k++ {
+		if math.Abs(sdb.Get(k)+20) > eps || math.Abs(il.Get(k)-20) > eps {
+			t.Errorf("dB doesn't match: got %v, %v want %v, %v\n", sdb.Get(k), il.Get(k), -20, 20)
+		}
+		if math.Abs(smag.Get(k)-0.1) > eps {
+			t.Errorf("Magnitude doesn't match: got %v want %v\n", smag.Get(k), 0.1)
+		}
+		if res := -net.Freq.W.Get(k) * tau * 180 / math.Pi; math.Abs(phase.Get(k)-res) > eps {
+			t.Errorf("Unwrapped phase doesn't match: got %v want %v\n", phase.Get(k), res)
+		}
+		if wrapped.Get(k) > 180 || wrapped.Get(k) <= -180 {
+			t.Errorf("Phase not wrapped: got %v\n", wrapped.Get(k))
+		}
+		if math.Abs(gd.Get(k)-tau) > eps {
+			t.Errorf("Group delay doesn't match: got %v want %v\n", gd.Get(k), tau)
+		}
+		if math.Abs(vswr.Get(k)-3) > eps || math.Abs(rl.Get(k)-20*math.Log10(2)) > eps {
+			t.Errorf("VSWR/return loss doesn't match: got %v, %v want %v, %v\n", vswr.Get(k), rl.Get(k), 3, 20*math.Log10(2))
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package gorf
+
+import (
+	"github.com/whipstein/golinalg/mat"
+)
+
+// OnePortCal is the 3-term error model of a one-port measurement
+//
+//	m = Ed + Er*g/(1 - Es*g)
+//
+// relating the raw reflection m to the actual reflection g
+type OnePortCal struct {
+	Freq *Frequency
+	Z0   *mat.CVector
+	Ed   *mat.CVector // directivity
+	Es   *mat.CVector // source match
+	Er   *mat.CVector // reflection tracking
+}
+
+// NewOnePortCal solves the error model from the raw measurements of three or
+// more standards, such as open, short and load, and their ideal responses.
+// All are 1-port networks on the same frequencies; with more than three
+// standards the error terms are a least squares fit.
+func NewOnePortCal(measured, ideal []*Network) *OnePortCal {
+	if len(measured) != len(ideal) {
+		panic("number of measured and ideal standards differ")
+	} else if len(measured) < 3 {
+		panic("one-port calibration needs at least 3 standards")
+	}
+
+	freq := measured[0].Freq
+	m := make([][]*mat.CMatrix, len(measured))
+	g := make([][]*mat.CMatrix, len(measured))
+	for i := range measured {
+		if measured[i].NPorts != 1 || ideal[i].NPorts != 1 {
+			panic("one-port standards must be 1-port networks")
+		} else if measured[i].Freq.NPts != freq.NPts || ideal[i].Freq.NPts != freq.NPts {
+			panic("standards must share the same frequencies")
+		}
+		m[i], g[i] = measured[i].S(), ideal[i].S()
+	}
+
+	c := &OnePortCal{
+		Freq: freq.DeepCopy(),
+		Z0:   measured[0].Z0.DeepCopy(),
+		Ed:   cvf(freq.NPts),
+		Es:   cvf(freq.NPts),
+		Er:   cvf(freq.NPts),
+	}
+
+	// m = Ed + g*m*Es - g*(Ed*Es - Er) is linear in Ed, Es and Ed*Es - Er
+	for k := 0; k < freq.NPts; k++ {
+		a := cmf(len(m), 3, opts)
+		b := cvf(len(m))
+		for i := range m {
+			mi, gi := m[i][k].Get(0, 0), g[i][k].Get(0, 0)
+			a.Set(i, 0, 1)
+			a.Set(i, 1, gi*mi)
+			a.Set(i, 2, -gi)
+			b.Set(i, mi)
+		}
+		x := clstsq(a, b)
+		c.Ed.Set(k, x.Get(0))
+		c.Es.Set(k, x.Get(1))
+		c.Er.Set(k, x.Get(0)*x.Get(1)-x.Get(2))
+	}
+
+	return c
+}
+
+// Apply returns the corrected 1-port network, in S, from the raw measurement
+func (c *OnePortCal) Apply(raw *Network) *Network {
+	return c.transform(raw, func(k int, m complex128) complex128 {
+		d := m - c.Ed.Get(k)
+		return d / (c.Er.Get(k) + c.Es.Get(k)*d)
+	})
+}
+
+// Embed returns the raw measurement, in S, that the error model predicts for
+// the 1-port network dut
+func (c *OnePortCal) Embed(dut *Network) *Network {
+	return c.transform(dut, func(k int, g complex128) complex128 {
+		return c.Ed.Get(k) + c.Er.Get(k)*g/(1-c.Es.Get(k)*g)
+	})
+}
+
+func (c *OnePortCal) transform(n *Network, f func(k int, x complex128) complex128) *Network {
+	if n.NPorts != 1 {
+		panic("one-port calibration applies to 1-port networks")
+	} else if n.Freq.NPts != c.Freq.NPts {
+		panic("network and calibration frequencies differ")
+	}
+
+	out := n.DeepCopy()
+	out.Data = n.S()
+	out.Param = S
+	for k := range out.Data {
+		out.Data[k].Set(0, 0, f(k, out.Data[k].Get(0, 0)))
+	}
+	return out
+}
+
+// ErrorTerms returns the directivity, source match and reflection tracking as
+// 1-port networks
+func (c *OnePortCal) ErrorTerms() (ed, es, er *Network) {
+	return c.errorTerm(c.Ed), c.errorTerm(c.Es), c.errorTerm(c.Er)
+}
+
+// errorTerm returns the error term e as a 1-port network
+func (c *OnePortCal) errorTerm(e *mat.CVector) *Network {
+	net := NewNetwork()
+	net.SetPorts(1)
+	net.Z0 = c.Z0.DeepCopy()
+	net.Param = S
+	net.Freq = c.Freq.DeepCopy()
+	for k := 0; k < c.Freq.NPts; k++ {
+		s := cmf(1, 1, opts)
+		s.Set(0, 0, e.Get(k))
+		net.Data = append(net.Data, s)
+	}
+	return net
+}
@@ -0,0 +1,64 @@
+package gorf
+
+import (
+	"math/cmplx"
+	"testing"
+)
+
+// onePortErrors returns a frequency dependent set of one-port error terms
+// on freq
+func onePortErrors(freq *Frequency) *OnePortCal {
+	c := &OnePortCal{Freq: freq.DeepCopy(), Z0: cvf(1), Ed: cvf(freq.NPts), Es: cvf(freq.NPts), Er: cvf(freq.NPts)}
+	c.Z0.SetReAll(50)
+	for k := 0; k < freq.NPts; k++ {
+		w := freq.W.Get(k)
+		c.Ed.Set(k, cmplx.Rect(0.05, w*1e-11))
+		c.Es.Set(k, cmplx.Rect(0.1, -w*2e-11))
+		c.Er.Set(k, cmplx.Rect(0.8, -w*1e-10))
+	}
+	return c
+}
+
+func TestOnePortCal(t *testing.T) {
+	dut := NewNetwork()
+	dut.ReadTouchstone("./data/delay_short.s1p")
+	freq := dut.Freq
+	truth := onePortErrors(freq)
+
+	tl := TEMLine{Z0: 50, EpsEff: 1}
+	ideal := []*Network{
+		Open(tl, freq, 1e-4, 50),
+		Short(tl, freq, 0.5e-4, 50),
+		constantNetwork(freq, 50, [][]complex128{{0}}),
+		constantNetwork(freq, 50, [][]complex128{{0.3 - 0.2i}}),
+	}
+	measured := make([]*Network, len(ideal))
+	for i := range ideal {
+		measured[i] = truth.Embed(ideal[i])
+	}
+
+	for _, nstd := range []int{3, 4} {
+		cal := NewOnePortCal(measured[:nstd], ideal[:nstd])
+		ed, es, er := cal.ErrorTerms()
+		for k := 0; k < freq.NPts; k++ {
+			for _, val := range []struct {
+				got, want complex128
+			}{
+				{ed.Data[k].Get(0, 0), truth.Ed.Get(k)},
+				{es.Data[k].Get(0, 0), truth.Es.Get(k)},
+				{er.Data[k].Get(0, 0), truth.Er.Get(k)},
+			} {
+				if cmplx.Abs(val.got-val.want) > eps {
+					t.Errorf("Error term doesn't match: got %v want %v\n", val.got, val.want)
+				}
+			}
+		}
+
+		got := cal.Apply(truth.Embed(dut))
+		for k := range got.Data {
+			if cmplx.Abs(got.Data[k].Get(0, 0)-dut.Data[k].Get(0, 0)) > eps {
+				t.Errorf("Corrected data doesn't match: got %v want %v\n", got.Data[k].Get(0, 0), dut.Data[k].Get(0, 0))
+			}
+		}
+	}
+}
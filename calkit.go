@@ -0,0 +1,191 @@
+package gorf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CalKit is a set of named calibration standard definitions with a common
+// reference impedance
+type CalKit struct {
+	Name      string
+	Z0        float64
+	Standards map[string]StandardDef
+}
+
+// calKitFile is the JSON layout of a cal kit, using the units of VNA cal kit
+// definitions: delay in ps, loss in Gohm/s, C0..C3 in fF, 1e-27 F/Hz,
+// 1e-36 F/Hz**2 and 1e-45 F/Hz**3 and L0..L3 in pH, 1e-24 H/Hz, 1e-33 H/Hz**2
+// and 1e-42 H/Hz**3
+type calKitFile struct {
+	Name      string           `json:"name"`
+	Z0        float64          `json:"z0"`
+	Standards []calKitStandard `json:"standards"`
+}
+
+type calKitStandard struct {
+	Name        string     `json:"name"`
+	Type        string     `json:"type"`
+	OffsetZ0    float64    `json:"offset_z0"`
+	OffsetDelay float64    `json:"offset_delay"`
+	OffsetLoss  float64    `json:"offset_loss"`
+	C           [4]float64 `json:"c"`
+	L           [4]float64 `json:"l"`
+	Load        [2]float64 `json:"load"` // real and imaginary parts (ohm)
+}
+
+var (
+	calKitC = [4]float64{1e-15, 1e-27, 1e-36, 1e-45}
+	calKitL = [4]float64{1e-12, 1e-24, 1e-33, 1e-42}
+)
+
+// ReadCalKit reads a cal kit from a JSON file (.json) or a text file.  The
+// text format has one keyword and its values per line, with the JSON names
+// and units, and ! comments:
+//
+//	name  example
+//	z0    50
+//	standard open open
+//	offset_delay 29.243
+//	c     49.43 -310.13 23.17 -0.16
+//	standard load load
+//	load  50 0
+//
+// where each standard line names a standard and its type (open, short, load
+// or thru) and starts its definition.
+func ReadCalKit(filename string) *CalKit {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		panic(err)
+	}
+
+	var f calKitFile
+	if strings.HasSuffix(strings.ToLower(filename), ".json") {
+		if err := json.Unmarshal(raw, &f); err != nil {
+			panic(err)
+		}
+	} else {
+		f = parseCalKit(raw)
+	}
+
+	kit := &CalKit{Name: f.Name, Z0: f.Z0, Standards: make(map[string]StandardDef)}
+	if kit.Z0 == 0 {
+		kit.Z0 = 50
+	}
+	for _, s := range f.Standards {
+		d := StandardDef{
+			OffsetZ0:    s.OffsetZ0,
+			OffsetDelay: s.OffsetDelay * 1e-12,
+			OffsetLoss:  s.OffsetLoss * 1e9,
+			Load:        complex(s.Load[0], s.Load[1]),
+		}
+		switch strings.ToLower(s.Type) {
+		case "open":
+			d.Type = OpenStandard
+		case "short":
+			d.Type = ShortStandard
+		case "load":
+			d.Type = LoadStandard
+		case "thru":
+			d.Type = ThruStandard
+		default:
+			panic(fmt.Sprintf("standard type %v of %v not recognized", s.Type, s.Name))
+		}
+		for i := range d.C {
+			d.C[i] = s.C[i] * calKitC[i]
+			d.L[i] = s.L[i] * calKitL[i]
+		}
+		if _, ok := kit.Standards[s.Name]; ok {
+			panic("duplicate standard " + s.Name)
+		}
+		kit.Standards[s.Name] = d
+	}
+
+	return kit
+}
+
+// calKitKeywords holds the number of values taken by each keyword of the
+// text cal kit format
+var calKitKeywords = map[string]int{
+	"name": 1, "z0": 1, "standard": 2,
+	"offset_z0": 1, "offset_delay": 1, "offset_loss": 1,
+	"c": 4, "l": 4, "load": 2,
+}
+
+// parseCalKit parses the text cal kit format into the JSON layout
+func parseCalKit(raw []byte) calKitFile {
+	var f calKitFile
+	for i, line := range bytes.Split(raw, []byte("\n")) {
+		if idx := bytes.IndexByte(line, '!'); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(string(line))
+		if len(fields) == 0 {
+			continue
+		}
+
+		key := strings.ToLower(fields[0])
+		if n, ok := calKitKeywords[key]; !ok {
+			panic(fmt.Sprintf("line %v: keyword %v not recognized", i+1, fields[0]))
+		} else if len(fields)-1 != n {
+			panic(fmt.Sprintf("line %v: %v takes %v values", i+1, fields[0], n))
+		}
+
+		switch key {
+		case "name":
+			f.Name = fields[1]
+			continue
+		case "standard":
+			f.Standards = append(f.Standards, calKitStandard{Name: fields[1], Type: fields[2]})
+			continue
+		}
+
+		vals := make([]float64, len(fields)-1)
+		for j, x := range fields[1:] {
+			v, err := strconv.ParseFloat(x, 64)
+			if err != nil {
+				panic(fmt.Sprintf("line %v: %v", i+1, err))
+			}
+			vals[j] = v
+		}
+		if key == "z0" {
+			f.Z0 = vals[0]
+			continue
+		} else if len(f.Standards) == 0 {
+			panic(fmt.Sprintf("line %v: %v outside a standard", i+1, fields[0]))
+		}
+
+		s := &f.Standards[len(f.Standards)-1]
+		switch key {
+		case "offset_z0":
+			s.OffsetZ0 = vals[0]
+		case "offset_delay":
+			s.OffsetDelay = vals[0]
+		case "offset_loss":
+			s.OffsetLoss = vals[0]
+		case "c":
+			copy(s.C[:], vals)
+		case "l":
+			copy(s.L[:], vals)
+		case "load":
+			copy(s.Load[:], vals)
+		}
+	}
+	return f
+}
+
+// Standard returns the named standard on freq as a network in S on the kit
+// reference impedance
+func (k *CalKit) Standard(name string, freq *Frequency) *Network {
+	d, ok := k.Standards[name]
+	if !ok {
+		panic("standard " + name + " not in cal kit")
+	}
+	net := d.Network(freq, k.Z0)
+	net.Name = name
+	return net
+}
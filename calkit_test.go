@@ -0,0 +1,69 @@
+package gorf
+
+import (
+	"math/cmplx"
+	"testing"
+)
+
+func TestReadCalKit(t *testing.T) {
+	freq := NewFrequencySweep(0.1, 26.5, 50, "ghz", Lin)
+	js := ReadCalKit("./data/calkit.json")
+	txt := ReadCalKit("./data/calkit.txt")
+
+	if js.Name != "example_3.5mm" || txt.Name != js.Name || len(js.Standards) != 4 || len(txt.Standards) != 4 {
+		t.Fatalf("Cal kit doesn't match: got %v %v and %v %v\n", js.Name, len(js.Standards), txt.Name, len(txt.Standards))
+	}
+
+	res := StandardDef{
+		Type:        OpenStandard,
+		OffsetZ0:    50,
+		OffsetDelay: 29.243e-12,
+		OffsetLoss:  2.2e9,
+		C:           [4]float64{49.43e-15, -310.13e-27, 23.17e-36, -0.16e-45},
+	}.Network(freq, 50)
+	for _, name := range []string{"open", "short", "load", "thru"} {
+		a, b := js.Standard(name, freq), txt.Standard(name, freq)
+		for k := range a.Data {
+			for i := 0; i < a.NPorts; i++ {
+				for j := 0; j < a.NPorts; j++ {
+					if a.Data[k].Get(i, j) != b.Data[k].Get(i, j) {
+						t.Errorf("Standard %v doesn't match: got %v want %v\n", name, b.Data[k].Get(i, j), a.Data[k].Get(i, j))
+					}
+				}
+			}
+			if name == "open" && cmplx.Abs(a.Data[k].Get(0, 0)-res.Data[k].Get(0, 0)) > eps {
+				t.Errorf("Open doesn't match: got %v want %v\n", a.Data[k].Get(0, 0), res.Data[k].Get(0, 0))
+			}
+			if name == "load" && cmplx.Abs(a.Data[k].Get(0, 0)) > eps {
+				t.Errorf("Load doesn't match: got %v want 0\n", a.Data[k].Get(0, 0))
+			}
+		}
+	}
+}
+
+func TestCalKitIdeal(t *testing.T) {
+	dut := NewNetwork()
+	dut.ReadTouchstone("./data/delay_short.s1p")
+	freq := dut.Freq
+	truth := onePortErrors(freq)
+	kit := ReadCalKit("./data/calkit_ideal.txt")
+
+	open := kit.Standard("open", freq)
+	for k := range open.Data {
+		if s := open.Data[k].Get(0, 0); cmplx.IsNaN(s) || cmplx.Abs(s-1) > eps {
+			t.Errorf("Ideal open doesn't match: got %v want 1\n", s)
+		}
+	}
+
+	var ideal, measured []*Network
+	for _, name := range []string{"open", "short", "load"} {
+		ideal = append(ideal, kit.Standard(name, freq))
+		measured = append(measured, truth.Embed(ideal[len(ideal)-1]))
+	}
+	got := NewOnePortCal(measured, ideal).Apply(truth.Embed(dut))
+	for k := range got.Data {
+		if s := got.Data[k].Get(0, 0); cmplx.IsNaN(s) || cmplx.Abs(s-dut.Data[k].Get(0, 0)) > eps {
+			t.Errorf("Corrected S11 doesn't match: got %v want %v\n", got.Data[k].Get(0, 0), dut.Data[k].Get(0, 0))
+		}
+	}
+}
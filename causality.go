@@ -0,0 +1,98 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+type FreqRange struct {
+	Start, Stop float64
+}
+
+type CausalityReport struct {
+	Freq   *mat.Vector     // harmonic frequency grid (Hz) the check is made on
+	Metric [][]float64     // fraction of impulse response energy before t = 0
+	Error  [][]*mat.Vector // |S - causal S|**2 relative to the mean of |S|**2
+	Ranges [][][]FreqRange // frequency ranges where Error exceeds tol
+}
+
+// CheckCausality tests each Sij against the Kramers-Kronig relations.  The
+// Kaiser windowed low pass spectrum is transformed to the time domain, the
+// response before t = 0 is removed, and the result is transformed back; for a
+// causal Sij this reproduces the spectrum, which is equivalent to its real and
+// imaginary parts forming a Hilbert transform pair.  The window spreads a
+// response at t = 0 over its main lobe, so only the response earlier than the
+// main lobe half-width is removed.  The per element metric is the fraction of
+// impulse response energy so removed, and the per frequency
+// error is the power of the difference between the windowed spectrum and its
+// causal part, relative to the mean windowed power.  Truncation at the top of
+// the band leaves an error of order 1e-4 there even for causal data.  The
+// network is reported causal if the error never exceeds tol.  Delays longer
+// than half the time span 1/df alias to negative time and must be avoided.
+func (n *Network) CheckCausality(tol float64) (bool, *CausalityReport) {
+	causal := true
+	rep := &CausalityReport{
+		Metric: make([][]float64, n.NPorts),
+		Error:  make([][]*mat.Vector, n.NPorts),
+		Ranges: make([][][]FreqRange, n.NPorts),
+	}
+
+	// half-width, in time samples, of the main lobe of the window's
+	// transform, which spreads even an impulse at t = 0 to either side
+	lobe := int(math.Ceil(math.Sqrt(1 + kaiserBeta*kaiserBeta/(math.Pi*math.Pi))))
+
+	for i := 0; i < n.NPorts; i++ {
+		rep.Metric[i] = make([]float64, n.NPorts)
+		rep.Error[i] = make([]*mat.Vector, n.NPorts)
+		rep.Ranges[i] = make([][]FreqRange, n.NPorts)
+		for j := 0; j < n.NPorts; j++ {
+			f, s := n.spectrum(i, j, LowPass)
+			if rep.Freq == nil {
+				rep.Freq = vdf(f)
+			}
+			_, h := toTime(f, s, LowPass, Kaiser)
+			w := Kaiser.coefficients(2*len(s) - 1)[len(s)-1:]
+
+			neg, total := 0., 0.
+			for k := range h {
+				e := math.Pow(cmplx.Abs(h[k]), 2)
+				total += e
+				if k < len(h)/2-lobe {
+					neg += e
+					h[k] = 0
+				}
+			}
+			if total > 0 {
+				rep.Metric[i][j] = neg / total
+			}
+
+			sc := fromTime(h)
+			power := 0.
+			for k := range f {
+				power += math.Pow(cmplx.Abs(s[k])*w[k], 2) / float64(len(f))
+			}
+
+			rep.Error[i][j] = vf(len(f))
+			inRange := false
+			for k := range f {
+				e := 0.
+				if power > 0 {
+					e = math.Pow(cmplx.Abs(s[k]*complex(w[k], 0)-sc[k]), 2) / power
+				}
+				rep.Error[i][j].Set(k, e)
+				if e > tol {
+					causal = false
+					if !inRange {
+						rep.Ranges[i][j] = append(rep.Ranges[i][j], FreqRange{f[k], f[k]})
+					}
+					rep.Ranges[i][j][len(rep.Ranges[i][j])-1].Stop = f[k]
+				}
+				inRange = e > tol
+			}
+		}
+	}
+
+	return causal, rep
+}
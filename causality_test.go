@@ -0,0 +1,66 @@
+package gorf
+
+import (
+	"math/cmplx"
+	"testing"
+)
+
+func TestCheckCausality(t *testing.T) {
+	net := delayNetwork(0.33e-9)
+	for k := range net.Data {
+		net.Data[k].Set(0, 0, 0.2*cmplx.Exp(complex(0, -net.Freq.W.Get(k)*1.1e-9)))
+	}
+
+	ok, rep := net.CheckCausality(1e-2)
+	if !ok {
+		t.Errorf("Causal network failed: got metric %v ranges %v\n", rep.Metric, rep.Ranges)
+	}
+
+	// S22 leads the excitation by 0.5 ns over 2-4 GHz
+	for k := 19; k < 40; k++ {
+		net.Data[k].Set(1, 1, 0.2*cmplx.Exp(complex(0, net.Freq.W.Get(k)*0.5e-9)))
+	}
+	ok, rep = net.CheckCausality(1e-2)
+	if ok {
+		t.Errorf("Non-causal network passed: got metric %v\n", rep.Metric)
+	}
+	if rep.Metric[1][1] < 10*rep.Metric[0][0] || rep.Metric[1][1] < 10*rep.Metric[1][0] {
+		t.Errorf("Non-causal element not identified: got metric %v\n", rep.Metric)
+	}
+	worst := 0
+	for k := range rep.Error[1][1].Data {
+		if rep.Error[1][1].Get(k) > rep.Error[1][1].Get(worst) {
+			worst = k
+		}
+	}
+	if f := rep.Freq.Get(worst); f < 1.5e9 || f > 4.5e9 {
+		t.Errorf("Worst error outside non-causal band: got %v\n", f)
+	}
+	if len(rep.Ranges[1][1]) == 0 {
+		t.Errorf("No offending range found\n")
+	}
+	if len(rep.Ranges[0][0])+len(rep.Ranges[0][1])+len(rep.Ranges[1][0]) != 0 {
+		t.Errorf("Causal element flagged: got %v\n", rep.Ranges)
+	}
+}
+
+func TestCheckCausalityZeroDelay(t *testing.T) {
+	freq := NewFrequencySweep(0.1, 20, 200, "ghz", Lin)
+	tee := NewNetwork()
+	tee.ReadTouchstone("./data/tee.s3p")
+	tests := []struct {
+		name string
+		net  *Network
+	}{
+		{"attenuator", Attenuator(freq, 6, 50)},
+		{"series resistor", SeriesImpedance(freq, Resistor(20, 0, 0), 50)},
+		{"series capacitor", SeriesImpedance(freq, Capacitor(1e-12, 0, 0, 0), 50)},
+		{"shunt inductor", ShuntImpedance(freq, Inductor(1e-9, 0, 0, 0), 50)},
+		{"tee", tee},
+	}
+	for _, test := range tests {
+		if ok, rep := test.net.CheckCausality(1e-2); !ok {
+			t.Errorf("%v causal network failed: got metric %v ranges %v\n", test.name, rep.Metric, rep.Ranges)
+		}
+	}
+}
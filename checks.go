@@ -0,0 +1,79 @@
+package gorf
+
+import (
+	"math/cmplx"
+
+	"github.com/whipstein/golinalg/goblas"
+	"github.com/whipstein/golinalg/golapack"
+	"github.com/whipstein/golinalg/mat"
+)
+
+type CheckReport struct {
+	Error     *mat.Vector // error metric at each frequency
+	Worst     float64     // largest error
+	WorstFreq float64     // frequency (Hz) of the largest error
+}
+
+// IsReciprocal reports whether S = S^T to within tol, using max|Sij - Sji| at
+// each frequency as the error
+func (n *Network) IsReciprocal(tol float64) (bool, *CheckReport) {
+	return n.check(tol, func(s *mat.CMatrix) float64 {
+		e := 0.
+		for i := 0; i < s.Rows; i++ {
+			for j := i + 1; j < s.Cols; j++ {
+				e = maxf64(e, cmplx.Abs(s.Get(i, j)-s.Get(j, i)))
+			}
+		}
+		return e
+	})
+}
+
+// IsSymmetric reports whether the network is unchanged when its ports are
+// numbered in reverse, Sij = S(N-1-i)(N-1-j), to within tol.  For a 2-port
+// this is S11 = S22 and S12 = S21.
+func (n *Network) IsSymmetric(tol float64) (bool, *CheckReport) {
+	return n.check(tol, func(s *mat.CMatrix) float64 {
+		e := 0.
+		for i := 0; i < s.Rows; i++ {
+			for j := 0; j < s.Cols; j++ {
+				e = maxf64(e, cmplx.Abs(s.Get(i, j)-s.Get(s.Rows-1-i, s.Cols-1-j)))
+			}
+		}
+		return e
+	})
+}
+
+// IsLossless reports whether S^H*S = I to within tol, using the largest
+// element of |S^H*S - I| at each frequency as the error
+func (n *Network) IsLossless(tol float64) (bool, *CheckReport) {
+	return n.check(tol, func(s *mat.CMatrix) float64 {
+		p := cmf(s.Rows, s.Cols, s.Opts)
+		golapack.Zlaset(mat.Full, s.Rows, s.Cols, 0, -1, p)
+		if err := goblas.Zgemm(mat.ConjTrans, mat.NoTrans, s.Rows, s.Cols, s.Cols, 1, s, s, 1, p); err != nil {
+			panic(err)
+		}
+
+		e := 0.
+		for _, val := range p.Data {
+			e = maxf64(e, cmplx.Abs(val))
+		}
+		return e
+	})
+}
+
+// check evaluates metric on the S-matrix at every frequency
+func (n *Network) check(tol float64, metric func(*mat.CMatrix) float64) (bool, *CheckReport) {
+	data := n.S()
+	rep := &CheckReport{Error: vf(n.Freq.NPts)}
+
+	for k := 0; k < n.Freq.NPts; k++ {
+		e := metric(data[k])
+		rep.Error.Set(k, e)
+		if k == 0 || e > rep.Worst {
+			rep.Worst = e
+			rep.WorstFreq = n.Freq.Freq.Get(k)
+		}
+	}
+
+	return rep.Worst <= tol, rep
+}
@@ -0,0 +1,34 @@
+package gorf
+
+import (
+	"math"
+	"testing"
+)
+
+func TestChecks(t *testing.T) {
+	net := delayNetwork(0.3e-9)
+
+	for _, check := range []func(float64) (bool, *CheckReport){net.IsReciprocal, net.IsSymmetric, net.IsLossless} {
+		if ok, rep := check(eps); !ok {
+			t.Errorf("Check failed: got worst %v at %v\n", rep.Worst, rep.WorstFreq)
+		}
+	}
+
+	net.Data[20].Set(1, 0, 0.5*net.Data[20].Get(1, 0))
+	net.Data[30].Set(0, 0, 0.2)
+	if ok, rep := net.IsReciprocal(eps); ok || math.Abs(rep.Worst-0.5) > eps || rep.WorstFreq != net.Freq.Freq.Get(20) {
+		t.Errorf("Reciprocity doesn't match: got %v at %v want %v at %v\n", rep.Worst, rep.WorstFreq, 0.5, net.Freq.Freq.Get(20))
+	}
+	if ok, rep := net.IsSymmetric(eps); ok || math.Abs(rep.Worst-0.5) > eps || math.Abs(rep.Error.Get(30)-0.2) > eps {
+		t.Errorf("Symmetry doesn't match: got %v, %v want %v, %v\n", rep.Worst, rep.Error.Get(30), 0.5, 0.2)
+	}
+	if ok, rep := net.IsLossless(eps); ok || math.Abs(rep.Error.Get(20)-0.75) > eps || rep.Error.Get(10) > eps {
+		t.Errorf("Losslessness doesn't match: got %v, %v want %v, %v\n", rep.Error.Get(20), rep.Error.Get(10), 0.75, 0)
+	}
+
+	hfss := NewNetwork()
+	hfss.ReadTouchstone("./data/hfss_threeport_DB_50Ohm.s3p")
+	if ok, rep := hfss.IsReciprocal(1e-3); !ok {
+		t.Errorf("Network not reciprocal: got worst %v at %v\n", rep.Worst, rep.WorstFreq)
+	}
+}
@@ -0,0 +1,106 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Wilkinson returns an ideal equal split Wilkinson divider with port 1 the
+// common port, matched and with isolated output ports
+func Wilkinson(freq *Frequency, z0 float64) *Network {
+	t := complex(0, -1/math.Sqrt2)
+	return constantNetwork(freq, z0, [][]complex128{
+		{0, t, t},
+		{t, 0, 0},
+		{t, 0, 0},
+	})
+}
+
+// Coupler returns an ideal matched quadrature directional coupler with
+// coupling and isolation in dB.  Port 1 is the input, 2 the through, 3 the
+// coupled and 4 the isolated port; the through path carries the remaining
+// power, so the coupler is lossless for infinite isolation.
+func Coupler(freq *Frequency, coupling, isolation, z0 float64) *Network {
+	c := complex(0, math.Pow(10, -coupling/20))
+	t := complex(math.Sqrt(1-math.Pow(10, -coupling/10)), 0)
+	i := complex(math.Pow(10, -isolation/20), 0)
+	return constantNetwork(freq, z0, [][]complex128{
+		{0, t, c, i},
+		{t, 0, i, c},
+		{c, i, 0, t},
+		{i, c, t, 0},
+	})
+}
+
+// Circulator returns an ideal nports circulator passing port i to port i+1
+// and the last port to port 1
+func Circulator(freq *Frequency, nports int, z0 float64) *Network {
+	s := make([][]complex128, nports)
+	for i := range s {
+		s[i] = make([]complex128, nports)
+	}
+	for i := 0; i < nports; i++ {
+		s[(i+1)%nports][i] = 1
+	}
+	return constantNetwork(freq, z0, s)
+}
+
+// Isolator returns an ideal matched isolator passing port 1 to port 2 with
+// the reverse path attenuated by isolation dB
+func Isolator(freq *Frequency, isolation, z0 float64) *Network {
+	return constantNetwork(freq, z0, [][]complex128{
+		{0, complex(math.Pow(10, -isolation/20), 0)},
+		{1, 0},
+	})
+}
+
+// Attenuator returns an ideal matched attenuator of loss dB
+func Attenuator(freq *Frequency, loss, z0 float64) *Network {
+	a := complex(math.Pow(10, -loss/20), 0)
+	return constantNetwork(freq, z0, [][]complex128{
+		{0, a},
+		{a, 0},
+	})
+}
+
+// PhaseShifter returns an ideal matched phase shifter with S21 = S12 =
+// exp(-j*phase), phase in degrees
+func PhaseShifter(freq *Frequency, phase, z0 float64) *Network {
+	p := cmplx.Rect(1, -phase*math.Pi/180)
+	return constantNetwork(freq, z0, [][]complex128{
+		{0, p},
+		{p, 0},
+	})
+}
+
+// Transformer returns an ideal transformer with turns ratio n:1 from port 1
+// to port 2
+func Transformer(freq *Frequency, n, z0 float64) *Network {
+	d := complex(n*n+1, 0)
+	r := complex(n*n-1, 0)
+	return constantNetwork(freq, z0, [][]complex128{
+		{r / d, complex(2*n, 0) / d},
+		{complex(2*n, 0) / d, -r / d},
+	})
+}
+
+// constantNetwork returns the network in S with real reference impedance z0
+// having the same scattering matrix s at every frequency of freq
+func constantNetwork(freq *Frequency, z0 float64, s [][]complex128) *Network {
+	net := NewNetwork()
+	net.SetPorts(len(s))
+	net.Z0.SetReAll(z0)
+	net.Param = S
+	net.Freq = freq.DeepCopy()
+
+	for k := 0; k < freq.NPts; k++ {
+		m := cmf(len(s), len(s), opts)
+		for i := range s {
+			for j := range s[i] {
+				m.Set(i, j, s[i][j])
+			}
+		}
+		net.Data = append(net.Data, m)
+	}
+	return net
+}
@@ -0,0 +1,51 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestComponents(t *testing.T) {
+	freq := NewFrequencySweep(1, 2, 3, "ghz", Lin)
+
+	if ok, rep := Coupler(freq, 3, math.Inf(1), 50).IsLossless(eps); !ok {
+		t.Errorf("Ideal coupler not lossless: got %v\n", rep.Worst)
+	}
+	if ok, rep := Circulator(freq, 3, 50).IsLossless(eps); !ok {
+		t.Errorf("Circulator not lossless: got %v\n", rep.Worst)
+	}
+	if ok, _ := Circulator(freq, 3, 50).IsReciprocal(eps); ok {
+		t.Errorf("Circulator reported reciprocal\n")
+	}
+	if ok, _ := Wilkinson(freq, 50).IsReciprocal(eps); !ok {
+		t.Errorf("Wilkinson not reciprocal\n")
+	}
+
+	c := Coupler(freq, 10, 30, 50).Data[0]
+	if db := 20 * math.Log10(cmplx.Abs(c.Get(2, 0))); math.Abs(db+10) > eps {
+		t.Errorf("Coupling doesn't match: got %v want %v\n", db, -10)
+	}
+	if db := 20 * math.Log10(cmplx.Abs(c.Get(3, 0))); math.Abs(db+30) > eps {
+		t.Errorf("Isolation doesn't match: got %v want %v\n", db, -30)
+	}
+
+	if s := Attenuator(freq, 6, 50).Sdb(1, 0); math.Abs(s.Get(2)+6) > eps {
+		t.Errorf("Attenuation doesn't match: got %v want %v\n", s.Get(2), -6)
+	}
+	if s := PhaseShifter(freq, 45, 50).Sphase(1, 0, false); math.Abs(s.Get(1)+45) > eps {
+		t.Errorf("Phase doesn't match: got %v want %v\n", s.Get(1), -45)
+	}
+
+	// 2:1 turns ratio presents 4 times the load impedance
+	tr := Transformer(freq, 2, 50)
+	if s := tr.Data[0].Get(0, 0); cmplx.Abs(s-0.6) > eps {
+		t.Errorf("Transformer match doesn't match: got %v want %v\n", s, 0.6)
+	}
+	if ok, rep := tr.IsLossless(eps); !ok {
+		t.Errorf("Transformer not lossless: got %v\n", rep.Worst)
+	}
+	if s := Isolator(freq, 20, 50).Data[0]; s.Get(1, 0) != 1 || cmplx.Abs(s.Get(0, 1)-0.1) > eps {
+		t.Errorf("Isolator doesn't match: got %v %v want 1 0.1\n", s.Get(1, 0), s.Get(0, 1))
+	}
+}
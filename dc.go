@@ -0,0 +1,101 @@
+package gorf
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+type DCReport struct {
+	Points      int          // low frequency points used for the trend
+	Scale       float64      // factor applied to the DC matrix for passivity
+	Regridded   bool         // data resampled onto a harmonic grid
+	DC          *mat.CMatrix // DC S-matrix added to the network
+	Assumptions []string
+}
+
+// ExtrapolateToDC returns a copy of the network, in S, with a DC point added.
+// Each Sij is extrapolated from the trend of its lowest frequency points: the
+// magnitude linearly and the phase to the nearest of 0 or pi, so the DC value
+// is real.  If the resulting DC matrix is not passive it is scaled until it
+// is.  With regrid the data is also resampled onto the harmonic grid
+// k*fstop/N used by the low pass time domain transform.  The report records
+// the DC matrix and the assumptions made in deriving it.
+func (n *Network) ExtrapolateToDC(regrid bool) (*Network, *DCReport) {
+	f := n.Freq.Freq.Data[:n.Freq.NPts]
+	if f[0] == 0 {
+		panic("network already contains a DC point")
+	}
+
+	sdata := n.S()
+	rep := &DCReport{
+		Points:    minint(len(f), dcFitPoints),
+		Scale:     1,
+		Regridded: regrid,
+		DC:        cmf(n.NPorts, n.NPorts, opts),
+	}
+
+	var fh []float64
+	var sh [][][]complex128
+	for i := 0; i < n.NPorts; i++ {
+		sh = append(sh, make([][]complex128, n.NPorts))
+		for j := 0; j < n.NPorts; j++ {
+			s := make([]complex128, len(f))
+			for k := range s {
+				s[k] = sdata[k].Get(i, j)
+			}
+
+			mag, ang, dcmag, dcang := dcExtrapolate(f, s)
+			rep.DC.Set(i, j, complex(dcmag*math.Cos(dcang), 0))
+			if regrid {
+				fh, sh[i][j] = harmonicResample(f, mag, ang, dcmag, dcang)
+			}
+		}
+	}
+
+	rep.Assumptions = append(rep.Assumptions,
+		fmt.Sprintf("DC magnitude of each Sij extrapolated linearly from the lowest %v points and limited to [0, 1]", rep.Points),
+		"DC phase of each Sij taken as the nearer of 0 and pi to the linear phase trend, giving a real DC value",
+	)
+
+	if p := Passivity(rep.DC); p < 0 {
+		rep.Scale = 1 / math.Sqrt(1-p)
+		for k := range rep.DC.Data {
+			rep.DC.Data[k] *= complex(rep.Scale, 0)
+		}
+		rep.Assumptions = append(rep.Assumptions, fmt.Sprintf("DC matrix scaled by %v to make it passive", rep.Scale))
+	}
+
+	out := n.DeepCopy()
+	out.Param = S
+	out.Freq = NewFrequency()
+	out.Freq.Unit = n.Freq.Unit
+	out.Freq.SweepType = n.Freq.SweepType
+	out.Data = make([]*mat.CMatrix, 0)
+
+	if regrid {
+		out.Freq.SweepType = Lin
+		rep.Assumptions = append(rep.Assumptions, fmt.Sprintf("data resampled onto %v harmonic points by linear interpolation of magnitude and unwrapped phase", len(fh)))
+		for k := range fh {
+			out.Freq.Append(fh[k] / float64(n.Freq.Unit))
+			m := cmf(n.NPorts, n.NPorts, opts)
+			for i := 0; i < n.NPorts; i++ {
+				for j := 0; j < n.NPorts; j++ {
+					m.Set(i, j, sh[i][j][k])
+				}
+			}
+			out.Data = append(out.Data, m)
+		}
+		out.Data[0] = rep.DC.DeepCopy()
+	} else {
+		out.Freq.Append(0)
+		out.Data = append(out.Data, rep.DC.DeepCopy())
+		for k := range f {
+			out.Freq.Append(n.Freq.FreqScaled.Get(k))
+			out.Data = append(out.Data, sdata[k])
+		}
+	}
+
+	return out, rep
+}
@@ -0,0 +1,53 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestExtrapolateToDC(t *testing.T) {
+	net := delayNetwork(0.3e-9)
+
+	out, rep := net.ExtrapolateToDC(false)
+	if out.Freq.NPts != net.Freq.NPts+1 || out.Freq.Freq.Get(0) != 0 {
+		t.Errorf("DC point not added: got %v points starting at %v\n", out.Freq.NPts, out.Freq.Freq.Get(0))
+	}
+	if cmplx.Abs(out.Data[0].Get(1, 0)-1) > eps || cmplx.Abs(out.Data[0].Get(0, 0)) > eps {
+		t.Errorf("DC value doesn't match: got %v want %v\n", out.Data[0], [][]complex128{{0, 1}, {1, 0}})
+	}
+	if rep.Scale != 1 || rep.Points != dcFitPoints {
+		t.Errorf("Report doesn't match: got %v\n", rep)
+	}
+
+	out, _ = net.ExtrapolateToDC(true)
+	if out.Freq.NPts != 101 {
+		t.Fatalf("Number of points doesn't match: got %v want %v\n", out.Freq.NPts, 101)
+	}
+	for k := 1; k < out.Freq.NPts; k++ {
+		if math.Abs(out.Freq.Freq.Get(k)-net.Freq.Freq.Get(k-1)) > 1 {
+			t.Errorf("Frequency doesn't match: got %v want %v\n", out.Freq.Freq.Get(k), net.Freq.Freq.Get(k-1))
+		}
+		if cmplx.Abs(out.Data[k].Get(1, 0)-net.Data[k-1].Get(1, 0)) > 1e-6 {
+			t.Errorf("Data doesn't match: got %v want %v\n", out.Data[k].Get(1, 0), net.Data[k-1].Get(1, 0))
+		}
+	}
+
+	for k := range net.Data {
+		net.Data[k].SetAll(0.8)
+	}
+	out, rep = net.ExtrapolateToDC(false)
+	if math.Abs(rep.Scale-1/1.6) > eps {
+		t.Errorf("Passivity scale doesn't match: got %v want %v\n", rep.Scale, 1/1.6)
+	}
+	if p := Passivity(out.Data[0]); p < -eps {
+		t.Errorf("DC point not passive: got %v\n", p)
+	}
+
+	// the scaled DC point carries through to the time domain
+	out, rep = net.ExtrapolateToDC(true)
+	_, st := out.StepResponse(1, 0, Kaiser)
+	if res := real(rep.DC.Get(1, 0)); math.Abs(st.Get(st.Size-1)-res) > eps {
+		t.Errorf("Step response doesn't settle at the DC value: got %v want %v\n", st.Get(st.Size-1), res)
+	}
+}
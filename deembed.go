@@ -0,0 +1,114 @@
+package gorf
+
+import (
+	"github.com/whipstein/golinalg/mat"
+)
+
+// DeembedOpen removes the shunt pad parasitics measured on an open dummy,
+//
+//	Ydut = Ym - Yopen
+//
+// and returns the intrinsic device in S
+func (n *Network) DeembedOpen(open *Network) *Network {
+	return n.deembed([]*Network{open}, func(m []*mat.CMatrix) *mat.CMatrix {
+		return csub(m[0], m[1])
+	})
+}
+
+// DeembedOpenShort removes the shunt pad parasitics measured on an open dummy
+// and then the series lead parasitics measured on a short dummy, the classic
+// two-step method
+//
+//	Zdut = (Ym - Yopen)^-1 - (Yshort - Yopen)^-1
+//
+// and returns the intrinsic device in S
+func (n *Network) DeembedOpenShort(open, short *Network) *Network {
+	return n.deembed([]*Network{open, short}, func(m []*mat.CMatrix) *mat.CMatrix {
+		zm := cmatInv(csub(m[0], m[1]))
+		zs := cmatInv(csub(m[2], m[1]))
+		return cmatInv(csub(zm, zs))
+	})
+}
+
+// DeembedThreeStep removes, from the outside in, the shunt pad parasitics
+// measured on a pad-only dummy, the series lead parasitics and the shunt
+// parasitics between the leads at the device.  The fixture is
+//
+//	Ypad || (Zlead + (Yinner || Ydut))
+//
+// with Zlead found from the short dummy and Yinner from the open dummy, both
+// of which include the leads.  It reduces to DeembedOpenShort when the leads
+// add no shunt coupling of their own.  Returns the intrinsic device in S.
+func (n *Network) DeembedThreeStep(pad, open, short *Network) *Network {
+	return n.deembed([]*Network{pad, open, short}, func(m []*mat.CMatrix) *mat.CMatrix {
+		zl := cmatInv(csub(m[3], m[1]))
+		yi := cmatInv(csub(cmatInv(csub(m[2], m[1])), zl))
+		zm := cmatInv(csub(m[0], m[1]))
+		return csub(cmatInv(csub(zm, zl)), yi)
+	})
+}
+
+// deembed applies f at each frequency to the Y-matrices of n followed by the
+// dummies and returns the resulting Y-matrices converted to S
+func (n *Network) deembed(dummies []*Network, f func(m []*mat.CMatrix) *mat.CMatrix) *Network {
+	y := [][]*mat.CMatrix{n.Y()}
+	for _, d := range dummies {
+		if d.NPorts != n.NPorts {
+			panic("network and dummy port counts differ")
+		} else if d.Freq.NPts != n.Freq.NPts {
+			panic("network and dummy frequencies differ")
+		}
+		y = append(y, d.Y())
+	}
+
+	out := n.DeepCopy()
+	for k := range out.Data {
+		m := make([]*mat.CMatrix, len(y))
+		for i := range y {
+			m[i] = y[i][k]
+		}
+		out.Data[k] = YtoS(f(m), out.Z0)
+	}
+	out.Param = S
+	return out
+}
+
+// cadd returns a + b
+func cadd(a, b *mat.CMatrix) *mat.CMatrix {
+	c := a.DeepCopy()
+	for i := range c.Data {
+		c.Data[i] += b.Data[i]
+	}
+	return c
+}
+
+// csub returns a - b
+func csub(a, b *mat.CMatrix) *mat.CMatrix {
+	c := a.DeepCopy()
+	for i := range c.Data {
+		c.Data[i] -= b.Data[i]
+	}
+	return c
+}
+
+// DeembedFixture removes the fixture halves left and right, such as those
+// from Split2xThru, either side of the device and returns it in S.  The
+// second half of the ports of left connect to the first half of the device
+// and its second half to the first half of right.
+func (n *Network) DeembedFixture(left, right *Network) *Network {
+	for _, d := range []*Network{left, right} {
+		if d.NPorts != n.NPorts {
+			panic("network and fixture port counts differ")
+		} else if d.Freq.NPts != n.Freq.NPts {
+			panic("network and fixture frequencies differ")
+		}
+	}
+
+	out := n.DeepCopy()
+	out.Param = S
+	tl, t, tr := left.T(), n.T(), right.T()
+	for k := range t {
+		out.Data[k] = TtoS(cmmul(cmmul(cmatInv(tl[k]), t[k]), cmatInv(tr[k])), out.Z0)
+	}
+	return out
+}
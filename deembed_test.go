@@ -0,0 +1,102 @@
+package gorf
+
+import (
+	"math/cmplx"
+	"testing"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+// yNetwork returns a 2-port network in Y with f giving the Y-matrix at each
+// angular frequency
+func yNetwork(freq *Frequency, f func(w float64) *mat.CMatrix) *Network {
+	net := NewNetwork()
+	net.SetPorts(2)
+	net.Z0.SetReAll(50)
+	net.Param = Y
+	net.Freq = freq.DeepCopy()
+	for k := 0; k < freq.NPts; k++ {
+		net.Data = append(net.Data, f(freq.W.Get(k)))
+	}
+	return net
+}
+
+func TestDeembed(t *testing.T) {
+	freq := NewFrequencySweep(1, 50, 25, "ghz", Lin)
+	ydut := func(w float64) *mat.CMatrix {
+		y := cmf(2, 2, opts)
+		y.Set(0, 0, complex(1e-4, w*50e-15))
+		y.Set(0, 1, complex(0, -w*10e-15))
+		y.Set(1, 0, complex(0.05, -w*10e-15))
+		y.Set(1, 1, complex(2e-3, w*30e-15))
+		return y
+	}
+	ypad := func(w float64) *mat.CMatrix {
+		y := cmf(2, 2, opts)
+		y.Set(0, 0, complex(0, w*25e-15))
+		y.Set(0, 1, complex(0, -w*5e-15))
+		y.Set(1, 0, complex(0, -w*5e-15))
+		y.Set(1, 1, complex(0, w*20e-15))
+		return y
+	}
+	zlead := func(w float64) *mat.CMatrix {
+		z := cmf(2, 2, opts)
+		z.Set(0, 0, complex(2, w*40e-12))
+		z.Set(0, 1, complex(0.5, w*5e-12))
+		z.Set(1, 0, complex(0.5, w*5e-12))
+		z.Set(1, 1, complex(3, w*50e-12))
+		return z
+	}
+	yinner := func(w float64) *mat.CMatrix {
+		y := cmf(2, 2, opts)
+		y.Set(0, 0, complex(0, w*4e-15))
+		y.Set(0, 1, complex(0, -w*1e-15))
+		y.Set(1, 0, complex(0, -w*1e-15))
+		y.Set(1, 1, complex(0, w*3e-15))
+		return y
+	}
+	// fixture returns Ypad || (Zlead + Y^-1) for the inner admittance y
+	fixture := func(w float64, y *mat.CMatrix) *mat.CMatrix {
+		return cadd(ypad(w), cmatInv(cadd(zlead(w), cmatInv(y))))
+	}
+
+	dut := yNetwork(freq, ydut)
+	pad := yNetwork(freq, ypad)
+	short := yNetwork(freq, func(w float64) *mat.CMatrix { return cadd(ypad(w), cmatInv(zlead(w))) })
+	open := yNetwork(freq, func(w float64) *mat.CMatrix { return fixture(w, yinner(w)) })
+	tests := []struct {
+		name string
+		got  *Network
+	}{
+		{"open", yNetwork(freq, func(w float64) *mat.CMatrix { return cadd(ypad(w), ydut(w)) }).DeembedOpen(pad)},
+		{"open-short", yNetwork(freq, func(w float64) *mat.CMatrix { return fixture(w, ydut(w)) }).DeembedOpenShort(pad, short)},
+		{"three-step", yNetwork(freq, func(w float64) *mat.CMatrix { return fixture(w, cadd(yinner(w), ydut(w))) }).DeembedThreeStep(pad, open, short)},
+	}
+
+	// the 2-port Y to S conversion in closed form for a real reference z0
+	want := make([]*mat.CMatrix, freq.NPts)
+	for k := range want {
+		y, z0 := dut.Data[k], complex(50, 0)
+		y11, y12, y21, y22 := z0*y.Get(0, 0), z0*y.Get(0, 1), z0*y.Get(1, 0), z0*y.Get(1, 1)
+		d := (1+y11)*(1+y22) - y12*y21
+		want[k] = cmf(2, 2, opts)
+		want[k].Set(0, 0, ((1-y11)*(1+y22)+y12*y21)/d)
+		want[k].Set(0, 1, -2*y12/d)
+		want[k].Set(1, 0, -2*y21/d)
+		want[k].Set(1, 1, ((1+y11)*(1-y22)+y12*y21)/d)
+	}
+	for _, test := range tests {
+		if test.got.Param != S {
+			t.Errorf("%v parameter doesn't match: got %v want %v\n", test.name, test.got.Param, S)
+		}
+		for k := range want {
+			for i := 0; i < 2; i++ {
+				for j := 0; j < 2; j++ {
+					if cmplx.Abs(test.got.Data[k].Get(i, j)-want[k].Get(i, j)) > 1e-9 {
+						t.Errorf("%v S%v%v doesn't match: got %v want %v\n", test.name, i+1, j+1, test.got.Data[k].Get(i, j), want[k].Get(i, j))
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package gorf
+
+import (
+	"math/cmplx"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+// FETModel is the small-signal equivalent circuit of a FET in common source.
+// The pad capacitances Cpg and Cpd are outermost, followed by the lead
+// inductances and access resistances, around the intrinsic device
+//
+//	Y11 = jw*Cgs/(1 + jw*Ri*Cgs) + jw*Cgd
+//	Y12 = -jw*Cgd
+//	Y21 = gm*exp(-jw*tau)/(1 + jw*Ri*Cgs) - jw*Cgd
+//	Y22 = 1/Rds + jw*(Cds + Cgd)
+//
+// Values are in SI units; Rds may be +Inf.
+type FETModel struct {
+	Lg, Ld, Ls, Rg, Rd, Rs, Cpg, Cpd float64 // parasitics
+	Gm, Tau, Cgs, Cgd, Cds, Rds, Ri  float64 // intrinsic
+}
+
+// FETExtraction holds the element values extracted at each frequency and
+// their averages as a frequency independent model
+type FETExtraction struct {
+	Freq   *Frequency
+	Values []FETModel
+	Model  FETModel
+}
+
+// fetIterations is the number of passes made between the cold FET
+// measurements to separate the pad capacitances from the series parasitics
+const fetIterations = 20
+
+// ExtractFET extracts the equivalent circuit from cold and hot FET
+// measurements on the same frequencies.  The parasitics come from two cold
+// FET measurements at Vds = 0: pinched, with the gate biased beyond pinch
+// off, and forward, with the channel open.  Pinched off the intrinsic device
+// is taken as equal gate-source and gate-drain capacitances Cb with Cds
+// neglected, giving
+//
+//	Y11 = jw*(Cpg + 2*Cb), Y12 = -jw*Cb, Y22 = jw*(Cpd + Cb)
+//
+// and forward it is a channel resistance rch, which must be known, giving
+//
+//	Z11 = Rg + Rs + rch/3 + jw*(Lg + Ls)
+//	Z12 = Rs + rch/2 + jw*Ls
+//	Z22 = Rd + Rs + rch + jw*(Ld + Ls)
+//
+// Each relation holds once the other parasitics are removed, so the two are
+// solved alternately.  The intrinsic elements then follow in closed form from
+// the hot FET with the parasitics removed.  The model is the average of the
+// values over frequency.
+func ExtractFET(pinched, forward, hot *Network, rch float64) *FETExtraction {
+	for _, n := range []*Network{pinched, forward, hot} {
+		if n.NPorts != 2 {
+			panic("FET measurements must be 2-port networks")
+		} else if n.Freq.NPts != hot.Freq.NPts {
+			panic("FET measurements must share the same frequencies")
+		}
+	}
+
+	e := &FETExtraction{Freq: hot.Freq.DeepCopy()}
+	yp, yf, yh := pinched.Y(), forward.Y(), hot.Y()
+	for k := 0; k < e.Freq.NPts; k++ {
+		w := e.Freq.W.Get(k)
+		var m FETModel
+		for it := 0; it < fetIterations; it++ {
+			zf := cmatInv(m.removePads(yf[k], w))
+			z12 := (zf.Get(0, 1) + zf.Get(1, 0)) / 2
+			m.Ls = imag(z12) / w
+			m.Lg = imag(zf.Get(0, 0))/w - m.Ls
+			m.Ld = imag(zf.Get(1, 1))/w - m.Ls
+			m.Rs = real(z12) - rch/2
+			m.Rg = real(zf.Get(0, 0)) - m.Rs - rch/3
+			m.Rd = real(zf.Get(1, 1)) - m.Rs - rch
+
+			yi := m.deembed(yp[k], w)
+			cb := -imag(yi.Get(0, 1)+yi.Get(1, 0)) / (2 * w)
+			m.Cpg += imag(yi.Get(0, 0))/w - 2*cb
+			m.Cpd += imag(yi.Get(1, 1))/w - cb
+		}
+
+		yi := m.deembed(yh[k], w)
+		a := 1 / (yi.Get(0, 0) + yi.Get(0, 1))
+		m.Ri = real(a)
+		m.Cgs = -1 / (w * imag(a))
+		m.Cgd = -imag(yi.Get(0, 1)) / w
+		b := (yi.Get(1, 0) - yi.Get(0, 1)) * complex(1, w*m.Ri*m.Cgs)
+		m.Gm = cmplx.Abs(b)
+		m.Tau = -cmplx.Phase(b) / w
+		d := yi.Get(1, 1) + yi.Get(0, 1)
+		m.Rds = 1 / real(d)
+		m.Cds = imag(d) / w
+
+		e.Values = append(e.Values, m)
+	}
+
+	n := float64(len(e.Values))
+	for _, v := range e.Values {
+		e.Model.Lg += v.Lg / n
+		e.Model.Ld += v.Ld / n
+		e.Model.Ls += v.Ls / n
+		e.Model.Rg += v.Rg / n
+		e.Model.Rd += v.Rd / n
+		e.Model.Rs += v.Rs / n
+		e.Model.Cpg += v.Cpg / n
+		e.Model.Cpd += v.Cpd / n
+		e.Model.Gm += v.Gm / n
+		e.Model.Tau += v.Tau / n
+		e.Model.Cgs += v.Cgs / n
+		e.Model.Cgd += v.Cgd / n
+		e.Model.Cds += v.Cds / n
+		e.Model.Rds += v.Rds / n
+		e.Model.Ri += v.Ri / n
+	}
+
+	return e
+}
+
+// Network returns the 2-port, in S with real reference impedance z0, of the
+// equivalent circuit on freq
+func (m FETModel) Network(freq *Frequency, z0 float64) *Network {
+	net := lumpedNetwork(freq, z0)
+	for k := 0; k < freq.NPts; k++ {
+		w := freq.W.Get(k)
+		y := cmatInv(cadd(cmatInv(m.intrinsic(w)), m.extrinsic(w)))
+		y.Set(0, 0, y.Get(0, 0)+complex(0, w*m.Cpg))
+		y.Set(1, 1, y.Get(1, 1)+complex(0, w*m.Cpd))
+		net.Data = append(net.Data, YtoS(y, net.Z0))
+	}
+	return net
+}
+
+// intrinsic returns the Y-matrix of the intrinsic device
+func (m FETModel) intrinsic(w float64) *mat.CMatrix {
+	gs := complex(0, w*m.Cgs) / complex(1, w*m.Ri*m.Cgs)
+	gd := complex(0, w*m.Cgd)
+	y := cmf(2, 2, opts)
+	y.Set(0, 0, gs+gd)
+	y.Set(0, 1, -gd)
+	y.Set(1, 0, complex(m.Gm, 0)*cmplx.Exp(complex(0, -w*m.Tau))/complex(1, w*m.Ri*m.Cgs)-gd)
+	y.Set(1, 1, complex(1/m.Rds, w*(m.Cds+m.Cgd)))
+	return y
+}
+
+// extrinsic returns the Z-matrix of the lead inductances and access
+// resistances
+func (m FETModel) extrinsic(w float64) *mat.CMatrix {
+	z := cmf(2, 2, opts)
+	z.Set(0, 0, complex(m.Rg+m.Rs, w*(m.Lg+m.Ls)))
+	z.Set(0, 1, complex(m.Rs, w*m.Ls))
+	z.Set(1, 0, complex(m.Rs, w*m.Ls))
+	z.Set(1, 1, complex(m.Rd+m.Rs, w*(m.Ld+m.Ls)))
+	return z
+}
+
+// removePads returns y with the pad capacitances removed
+func (m FETModel) removePads(y *mat.CMatrix, w float64) *mat.CMatrix {
+	out := y.DeepCopy()
+	out.Set(0, 0, out.Get(0, 0)-complex(0, w*m.Cpg))
+	out.Set(1, 1, out.Get(1, 1)-complex(0, w*m.Cpd))
+	return out
+}
+
+// deembed returns the intrinsic Y-matrix from the measured y
+func (m FETModel) deembed(y *mat.CMatrix, w float64) *mat.CMatrix {
+	return cmatInv(csub(cmatInv(m.removePads(y, w)), m.extrinsic(w)))
+}
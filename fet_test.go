@@ -0,0 +1,55 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestExtractFET(t *testing.T) {
+	freq := NewFrequencySweep(0.5, 40, 80, "ghz", Lin)
+	model := FETModel{
+		Lg: 40e-12, Ld: 35e-12, Ls: 5e-12, Rg: 2, Rd: 3, Rs: 1.5, Cpg: 20e-15, Cpd: 25e-15,
+		Gm: 0.08, Tau: 1.5e-12, Cgs: 150e-15, Cgd: 20e-15, Cds: 40e-15, Rds: 250, Ri: 4,
+	}
+	rch := 5.
+
+	cold := model
+	cold.Gm, cold.Tau, cold.Cds, cold.Rds, cold.Ri = 0, 0, 0, math.Inf(1), 0
+	cold.Cgs, cold.Cgd = 10e-15, 10e-15
+	pinched := cold.Network(freq, 50)
+	forward := lumpedNetwork(freq, 50)
+	for k := 0; k < freq.NPts; k++ {
+		w := freq.W.Get(k)
+		z := model.extrinsic(w)
+		z.Set(0, 0, z.Get(0, 0)+complex(rch/3, 0))
+		z.Set(0, 1, z.Get(0, 1)+complex(rch/2, 0))
+		z.Set(1, 0, z.Get(1, 0)+complex(rch/2, 0))
+		z.Set(1, 1, z.Get(1, 1)+complex(rch, 0))
+		y := cmatInv(z)
+		y.Set(0, 0, y.Get(0, 0)+complex(0, w*model.Cpg))
+		y.Set(1, 1, y.Get(1, 1)+complex(0, w*model.Cpd))
+		forward.Data = append(forward.Data, YtoS(y, forward.Z0))
+	}
+	hot := model.Network(freq, 50)
+
+	e := ExtractFET(pinched, forward, hot, rch)
+	for _, m := range append(e.Values, e.Model) {
+		got := []float64{m.Lg, m.Ld, m.Ls, m.Rg, m.Rd, m.Rs, m.Cpg, m.Cpd, m.Gm, m.Tau, m.Cgs, m.Cgd, m.Cds, m.Rds, m.Ri}
+		want := []float64{model.Lg, model.Ld, model.Ls, model.Rg, model.Rd, model.Rs, model.Cpg, model.Cpd, model.Gm, model.Tau, model.Cgs, model.Cgd, model.Cds, model.Rds, model.Ri}
+		for i := range got {
+			if math.Abs(got[i]-want[i]) > 1e-6*math.Abs(want[i]) {
+				t.Errorf("Element %v doesn't match: got %v want %v\n", i, got[i], want[i])
+			}
+		}
+	}
+
+	fit := e.Model.Network(freq, 50)
+	for k := range fit.Data {
+		for i := range fit.Data[k].Data {
+			if cmplx.Abs(fit.Data[k].Data[i]-hot.Data[k].Data[i]) > 1e-6 {
+				t.Errorf("Model S doesn't match: got %v want %v\n", fit.Data[k].Data[i], hot.Data[k].Data[i])
+			}
+		}
+	}
+}
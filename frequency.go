@@ -10,7 +10,7 @@ import (
 type FreqUnit int
 
 const (
-	Hz  FreqUnit = iota
+	Hz  FreqUnit = 1
 	KHz          = 1e3
 	MHz          = 1e6
 	GHz          = 1e9
@@ -39,6 +39,33 @@ func NewFrequency() *Frequency {
 	return &Frequency{Freq: vf(0), FreqScaled: vf(0), W: vf(0)}
 }
 
+// NewFrequencySweep returns npts frequencies from start to stop, given in
+// unit, spaced linearly or logarithmically
+func NewFrequencySweep(start, stop float64, npts int, unit string, sweep Sweep) *Frequency {
+	if npts < 1 || (sweep == Log && (start <= 0 || stop <= 0)) {
+		panic("invalid frequency sweep")
+	}
+
+	f := NewFrequency().Setup(unit)
+	f.SweepType = sweep
+	for k := 0; k < npts; k++ {
+		x := 0.
+		if npts > 1 {
+			x = float64(k) / float64(npts-1)
+		}
+		switch sweep {
+		case Lin:
+			f.Append(start + (stop-start)*x)
+		case Log:
+			f.Append(start * math.Pow(stop/start, x))
+		default:
+			panic("sweep type not recognized")
+		}
+	}
+
+	return f
+}
+
 func (f *Frequency) Setup(unit string) *Frequency {
 	switch strings.ToLower(unit) {
 	case "hz":
@@ -67,3 +94,16 @@ func (f *Frequency) Append(x float64) *Frequency {
 	f.W.Append(x * float64(f.Unit) * 2 * math.Pi)
 	return f
 }
+
+func (f *Frequency) DeepCopy() *Frequency {
+	return &Frequency{
+		Start:      f.Start,
+		Stop:       f.Stop,
+		NPts:       f.NPts,
+		Unit:       f.Unit,
+		SweepType:  f.SweepType,
+		Freq:       f.Freq.DeepCopy(),
+		FreqScaled: f.FreqScaled.DeepCopy(),
+		W:          f.W.DeepCopy(),
+	}
+}
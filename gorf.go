@@ -18,6 +18,9 @@ const (
 	T
 )
 
+// speed of light in vacuum (m/s)
+const c0 = 299792458.
+
 type Encoding int
 
 const (
@@ -30,6 +33,8 @@ var cmf = mat.CMatrixFactory()
 var cvf = mat.CVectorFactory()
 var mf = mat.MatrixFactory()
 var vf = mat.VectorFactory()
+var cvdf = mat.CVectorDataFactory()
+var vdf = mat.VectorDataFactory()
 
 // var opts = mat.NewMatOptsCol()
 var opts = mat.NewMatOpts()
@@ -0,0 +1,100 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+type LineParams struct {
+	Freq   *Frequency
+	Zc     *mat.CVector // characteristic impedance (ohm)
+	Gamma  *mat.CVector // propagation constant (1/m)
+	Alpha  *mat.Vector  // attenuation (dB/m)
+	EpsEff *mat.Vector  // effective permittivity from the phase constant
+}
+
+// ExtractLineParams extracts the parameters of a uniform transmission line
+// from one or two 2-port measurements of it, of the given lengths (m).
+//
+// The propagation constant comes from the eigenvalues exp(-+gamma*l) of a
+// T-matrix, of which the one with magnitude below 1 is exp(-gamma*l).  With
+// one line this is the T-matrix of the line itself, measured at its ends.
+// With two lines it is Mlong*inv(Mshort), which is similar to the T-matrix of
+// their difference in length, so identical transitions or fixtures at their
+// ends drop out.  The phase of gamma*l is
+// unwrapped over frequency and its 2*pi ambiguity set from the group delay,
+// so the frequency step must keep the phase change per step below pi.
+//
+// The characteristic impedance is sqrt(B/C) of the ABCD matrix of the longest
+// line, which is only exact for a line measured at its ends.
+func ExtractLineParams(lines []*Network, lengths []float64) *LineParams {
+	if len(lines) != len(lengths) || len(lines) < 1 || len(lines) > 2 {
+		panic("line extraction needs a length for each of one or two lines")
+	}
+	for _, n := range lines {
+		if n.NPorts != 2 {
+			panic("lines must be 2-port networks")
+		} else if n.Freq.NPts != lines[0].Freq.NPts {
+			panic("lines must share the same frequencies")
+		}
+	}
+
+	long, length := 0, lengths[0]
+	if len(lines) == 2 {
+		if lengths[1] > lengths[0] {
+			long = 1
+		}
+		length = math.Abs(lengths[1] - lengths[0])
+	}
+	if length <= 0 {
+		panic("line lengths must differ")
+	}
+
+	freq := lines[long].Freq
+	t := lines[long].T()
+	if len(lines) == 2 {
+		ts := lines[1-long].T()
+		for k := range t {
+			t[k] = cmmul(t[k], cmatInv(ts[k]))
+		}
+	}
+	e := make([]complex128, freq.NPts)
+	for k := range e {
+		l := ceig(t[k])
+		if cmplx.Abs(l[1]) < cmplx.Abs(l[0]) {
+			l[0], l[1] = l[1], l[0]
+		}
+		// without loss the roots have equal magnitude, and the one with
+		// negative phase at the first frequency, then the one nearest the
+		// previous root, is kept
+		if cmplx.Abs(l[1])-cmplx.Abs(l[0]) < 1e-9*cmplx.Abs(l[1]) {
+			if (k == 0 && imag(l[1]) < imag(l[0])) || (k > 0 && cmplx.Abs(l[1]-e[k-1]) < cmplx.Abs(l[0]-e[k-1])) {
+				l[0] = l[1]
+			}
+		}
+		e[k] = l[0]
+	}
+	abcd := lines[long].A()
+	g := propagation(freq, e, length, 0)
+
+	p := &LineParams{
+		Freq:   freq.DeepCopy(),
+		Zc:     cvf(freq.NPts),
+		Gamma:  cvdf(g),
+		Alpha:  vf(freq.NPts),
+		EpsEff: vf(freq.NPts),
+	}
+	for k := range g {
+		zc := cmplx.Sqrt(abcd[k].Get(0, 1) / abcd[k].Get(1, 0))
+		if real(zc) < 0 {
+			zc = -zc
+		}
+		p.Zc.Set(k, zc)
+		p.Alpha.Set(k, 20*math.Log10(math.E)*real(g[k]))
+		p.EpsEff.Set(k, math.Pow(imag(g[k])*c0/freq.W.Get(k), 2))
+	}
+
+	return p
+}
@@ -0,0 +1,48 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestExtractLineParams(t *testing.T) {
+	freq := NewFrequencySweep(0.1, 20, 200, "ghz", Lin)
+	tl := TEMLine{Z0: 60, EpsEff: 2.5, AlphaC: 3, TanD: 0.002}
+	matched := TEMLine{Z0: 50, EpsEff: 2.5, AlphaC: 3, TanD: 0.002}
+	a := errorBox(freq, 0.1+0.05i, 0.9, -0.08+0.1i, 20e-12)
+	b := errorBox(freq, 0.05-0.1i, 0.85i, 0.12, 15e-12)
+
+	tests := []struct {
+		name    string
+		tl      TEMLine
+		lines   []*Network
+		lengths []float64
+		zc      bool
+	}{
+		{"one line", tl, []*Network{Line(tl, freq, 30e-3, 50)}, []float64{30e-3}, true},
+		{"two lines", tl, []*Network{Line(tl, freq, 10e-3, 50), Line(tl, freq, 60e-3, 50)}, []float64{10e-3, 60e-3}, true},
+		{"two fixtured lines", tl, []*Network{Cascade(a, Line(tl, freq, 60e-3, 50), b), Cascade(a, Line(tl, freq, 10e-3, 50), b)}, []float64{60e-3, 10e-3}, false},
+		{"one matched line", matched, []*Network{Line(matched, freq, 30e-3, 50)}, []float64{30e-3}, true},
+		{"two matched lines", matched, []*Network{Line(matched, freq, 10e-3, 50), Line(matched, freq, 40e-3, 50)}, []float64{10e-3, 40e-3}, true},
+	}
+	for _, test := range tests {
+		p := ExtractLineParams(test.lines, test.lengths)
+		for k := 0; k < freq.NPts; k++ {
+			w := freq.W.Get(k)
+			g := test.tl.Gamma(w)
+			if cmplx.IsNaN(p.Gamma.Get(k)) || cmplx.Abs(p.Gamma.Get(k)-g) > 1e-6*cmplx.Abs(g) {
+				t.Errorf("%v gamma doesn't match: got %v want %v\n", test.name, p.Gamma.Get(k), g)
+			}
+			if alpha := 20 * math.Log10(math.E) * real(g); math.Abs(p.Alpha.Get(k)-alpha) > 1e-6*alpha {
+				t.Errorf("%v alpha doesn't match: got %v want %v\n", test.name, p.Alpha.Get(k), alpha)
+			}
+			if eps := math.Pow(imag(g)*c0/w, 2); math.Abs(p.EpsEff.Get(k)-eps) > 1e-6*eps {
+				t.Errorf("%v eps doesn't match: got %v want %v\n", test.name, p.EpsEff.Get(k), eps)
+			}
+			if test.zc && cmplx.Abs(p.Zc.Get(k)-complex(test.tl.Z0, 0)) > 1e-6 {
+				t.Errorf("%v Zc doesn't match: got %v want %v\n", test.name, p.Zc.Get(k), test.tl.Z0)
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Immittance is an impedance or admittance as a function of angular frequency
+// w (rad/s)
+type Immittance func(w float64) complex128
+
+// SeriesImpedance returns the 2-port, in S with real reference impedance z0,
+// of impedance z in series between the ports
+func SeriesImpedance(freq *Frequency, z Immittance, z0 float64) *Network {
+	net := lumpedNetwork(freq, z0)
+	for k := 0; k < freq.NPts; k++ {
+		s := cmf(2, 2, opts)
+		zk := z(freq.W.Get(k))
+		if cmplx.IsInf(zk) {
+			s.Set(0, 0, 1)
+			s.Set(1, 1, 1)
+		} else {
+			d := zk + complex(2*z0, 0)
+			s.Set(0, 0, zk/d)
+			s.Set(0, 1, complex(2*z0, 0)/d)
+			s.Set(1, 0, complex(2*z0, 0)/d)
+			s.Set(1, 1, zk/d)
+		}
+		net.Data = append(net.Data, s)
+	}
+	return net
+}
+
+// ShuntAdmittance returns the 2-port, in S with real reference impedance z0,
+// of admittance y from the through connection to ground
+func ShuntAdmittance(freq *Frequency, y Immittance, z0 float64) *Network {
+	net := lumpedNetwork(freq, z0)
+	for k := 0; k < freq.NPts; k++ {
+		s := cmf(2, 2, opts)
+		yk := y(freq.W.Get(k)) * complex(z0, 0)
+		if cmplx.IsInf(yk) {
+			s.Set(0, 0, -1)
+			s.Set(1, 1, -1)
+		} else {
+			d := yk + 2
+			s.Set(0, 0, -yk/d)
+			s.Set(0, 1, 2/d)
+			s.Set(1, 0, 2/d)
+			s.Set(1, 1, -yk/d)
+		}
+		net.Data = append(net.Data, s)
+	}
+	return net
+}
+
+// ShuntImpedance returns the 2-port of impedance z from the through
+// connection to ground
+func ShuntImpedance(freq *Frequency, z Immittance, z0 float64) *Network {
+	return ShuntAdmittance(freq, func(w float64) complex128 { return 1 / z(w) }, z0)
+}
+
+// lumpedNetwork returns an empty 2-port in S on freq
+func lumpedNetwork(freq *Frequency, z0 float64) *Network {
+	net := NewNetwork()
+	net.SetPorts(2)
+	net.Z0.SetReAll(z0)
+	net.Param = S
+	net.Freq = freq.DeepCopy()
+	return net
+}
+
+// Resistor returns the impedance of resistance r with series inductance ls
+// and parallel capacitance cp, either of which may be 0
+func Resistor(r, ls, cp float64) Immittance {
+	return func(w float64) complex128 {
+		return parallel(complex(r, w*ls), capacitance(w, cp))
+	}
+}
+
+// Inductor returns the impedance of inductance l with quality factor q at
+// frequency fq (Hz) and parallel capacitance cp.  The loss is a fixed series
+// resistance 2*pi*fq*l/q; q = 0 is lossless.
+func Inductor(l, q, fq, cp float64) Immittance {
+	rs := 0.
+	if q > 0 {
+		rs = 2 * math.Pi * fq * l / q
+	}
+	return func(w float64) complex128 {
+		return parallel(complex(rs, w*l), capacitance(w, cp))
+	}
+}
+
+// Capacitor returns the impedance of capacitance c with quality factor q at
+// frequency fq (Hz) and series inductance ls.  The loss is a fixed series
+// resistance 1/(2*pi*fq*c*q); q = 0 is lossless.
+func Capacitor(c, q, fq, ls float64) Immittance {
+	rs := 0.
+	if q > 0 {
+		rs = 1 / (2 * math.Pi * fq * c * q)
+	}
+	return func(w float64) complex128 {
+		return complex(rs, w*ls) + capacitance(w, c)
+	}
+}
+
+// SeriesRLC returns the impedance of r, l and c in series; c = 0 leaves out
+// the capacitor
+func SeriesRLC(r, l, c float64) Immittance {
+	return func(w float64) complex128 {
+		z := complex(r, w*l)
+		if c != 0 {
+			z += capacitance(w, c)
+		}
+		return z
+	}
+}
+
+// ParallelRLC returns the impedance of r, l and c in parallel; r = 0 or l = 0
+// leaves out that element
+func ParallelRLC(r, l, c float64) Immittance {
+	return func(w float64) complex128 {
+		y := complex(0, w*c)
+		if r != 0 {
+			y += complex(1/r, 0)
+		}
+		if l != 0 {
+			y += 1 / complex(0, w*l)
+		}
+		return 1 / y
+	}
+}
+
+// capacitance returns the impedance of capacitance c, infinite for c = 0
+func capacitance(w, c float64) complex128 {
+	if w*c == 0 {
+		return cmplx.Inf()
+	}
+	return 1 / complex(0, w*c)
+}
+
+// parallel returns the parallel combination of impedances a and b
+func parallel(a, b complex128) complex128 {
+	if cmplx.IsInf(a) {
+		return b
+	} else if cmplx.IsInf(b) {
+		return a
+	}
+	return a * b / (a + b)
+}
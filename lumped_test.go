@@ -0,0 +1,54 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestNewFrequencySweep(t *testing.T) {
+	f := NewFrequencySweep(1, 100, 3, "mhz", Log)
+	for k, res := range []float64{1e6, 10e6, 100e6} {
+		if math.Abs(f.Freq.Get(k)-res) > eps*res {
+			t.Errorf("Frequency doesn't match: got %v want %v\n", f.Freq.Get(k), res)
+		}
+	}
+	if f.NPts != 3 || f.Start != 1 || f.Stop != 100 {
+		t.Errorf("Sweep doesn't match: got %v points from %v to %v\n", f.NPts, f.Start, f.Stop)
+	}
+}
+
+func TestLumped(t *testing.T) {
+	freq := NewFrequencySweep(0.1, 10, 100, "ghz", Lin)
+
+	net := SeriesImpedance(freq, Resistor(50, 0, 0), 50)
+	for k := range net.Data {
+		if cmplx.Abs(net.Data[k].Get(0, 0)-1./3) > eps || cmplx.Abs(net.Data[k].Get(1, 0)-2./3) > eps {
+			t.Errorf("Series resistor doesn't match: got %v %v want %v %v\n", net.Data[k].Get(0, 0), net.Data[k].Get(1, 0), 1./3, 2./3)
+		}
+	}
+
+	// a shunt tank resonating at 1 GHz is an open, leaving a through
+	l, c := 1e-9, 1/(math.Pow(2*math.Pi*1e9, 2)*1e-9)
+	tank := ShuntImpedance(NewFrequencySweep(1, 1, 1, "ghz", Lin), ParallelRLC(0, l, c), 50)
+	if s := tank.Data[0]; cmplx.Abs(s.Get(0, 0)) > eps || cmplx.Abs(s.Get(1, 0)-1) > eps {
+		t.Errorf("Shunt tank doesn't match: got %v %v want 0 1\n", s.Get(0, 0), s.Get(1, 0))
+	}
+
+	// a shunt series resonator is a short at resonance
+	short := ShuntImpedance(NewFrequencySweep(1, 1, 1, "ghz", Lin), SeriesRLC(0, l, c), 50)
+	if s := short.Data[0]; cmplx.Abs(s.Get(0, 0)+1) > eps || cmplx.Abs(s.Get(1, 0)) > eps {
+		t.Errorf("Shunt series resonator doesn't match: got %v %v want -1 0\n", s.Get(0, 0), s.Get(1, 0))
+	}
+
+	w := 2 * math.Pi * 2e9
+	if z := Inductor(1e-9, 40, 2e9, 0)(w); math.Abs(imag(z)/real(z)-40) > eps {
+		t.Errorf("Inductor Q doesn't match: got %v want %v\n", imag(z)/real(z), 40)
+	}
+	if z := Capacitor(1e-12, 100, 2e9, 0)(w); math.Abs(-imag(z)/real(z)-100) > eps {
+		t.Errorf("Capacitor Q doesn't match: got %v want %v\n", -imag(z)/real(z), 100)
+	}
+	if z := Capacitor(1e-12, 0, 0, 1e-9)(1 / math.Sqrt(1e-21)); cmplx.Abs(z) > eps {
+		t.Errorf("Capacitor self resonance doesn't match: got %v want 0\n", z)
+	}
+}
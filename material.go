@@ -0,0 +1,156 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+// Material holds the relative permittivity and permeability of a sample at
+// each frequency, with losses as negative imaginary parts
+type Material struct {
+	Freq *Frequency
+	EpsR *mat.CVector
+	MuR  *mat.CVector
+}
+
+// nistIterations is the maximum number of Newton steps taken at each
+// frequency by ExtractNIST
+const nistIterations = 50
+
+// ExtractNRW extracts the permittivity and permeability of a sample of length
+// (m) filling a fixture with cutoff frequency fc (Hz), 0 for coax, by the
+// Nicolson-Ross-Weir method.  The network is the 2-port measurement with its
+// reference planes at the faces of the sample.  The phase of the transmission
+// through the sample is unwrapped over frequency and its 2*pi ambiguity set
+// from the group delay, so the frequency step must keep the phase change per
+// step below pi.  A sample matched to the fixture gives no reflection and is
+// handled, but for a mismatched sample the result is unreliable near
+// frequencies where it is a multiple of half a wavelength long, where S11
+// vanishes.
+func ExtractNRW(n *Network, length, fc float64) *Material {
+	if n.NPorts != 2 {
+		panic("material extraction requires a 2-port network")
+	}
+
+	s11, s21 := n.sij(0, 0), n.sij(1, 0)
+	gamma := make([]complex128, len(s11))
+	t := make([]complex128, len(s11))
+	for k := range s11 {
+		// the root of s11*g^2 - b*g + s11 = 0 with |g| <= 1, in a form that
+		// goes to 0 with s11 rather than dividing by it
+		b := s11[k]*s11[k] - s21[k]*s21[k] + 1
+		d := cmplx.Sqrt(b*b - 4*s11[k]*s11[k])
+		if cmplx.Abs(b-d) > cmplx.Abs(b+d) {
+			d = -d
+		}
+		if b+d != 0 {
+			gamma[k] = 2 * s11[k] / (b + d)
+		}
+		t[k] = (s11[k] + s21[k] - gamma[k]) / (1 - (s11[k]+s21[k])*gamma[k])
+	}
+	g := propagation(n.Freq, t, length, fc)
+
+	m := &Material{Freq: n.Freq.DeepCopy(), EpsR: cvf(len(t)), MuR: cvf(len(t))}
+	kc := 2 * math.Pi * fc / c0
+	for k := range t {
+		w := n.Freq.W.Get(k)
+		g0 := cmplx.Sqrt(complex(kc*kc-w*w/(c0*c0), 0))
+		mu := g[k] / g0 * (1 + gamma[k]) / (1 - gamma[k])
+		m.MuR.Set(k, mu)
+		m.EpsR.Set(k, (complex(kc*kc, 0)-g[k]*g[k])*complex(c0*c0/(w*w), 0)/mu)
+	}
+
+	return m
+}
+
+// ExtractNIST extracts the permittivity of a non-magnetic sample of length
+// (m) filling a fixture with cutoff frequency fc (Hz), 0 for coax, by the NIST
+// iterative method.  With mu = 1 the sum of the transmission and reflection
+// terms
+//
+//	(S21 + S12 + S11 + S22)/2 = (z*(1 - g^2) + g*(1 - z^2))/(1 - z^2*g^2)
+//
+// is solved for eps by Newton iteration, where z is the transmission through
+// the sample and g the reflection at its face.  The first frequency starts
+// from the first finite NRW result and each following one from its
+// predecessor, which keeps the solution on one branch and through the half
+// wavelength points where NRW fails.
+func ExtractNIST(n *Network, length, fc float64) *Material {
+	if n.NPorts != 2 {
+		panic("material extraction requires a 2-port network")
+	}
+
+	s11, s12, s21, s22 := n.sij(0, 0), n.sij(0, 1), n.sij(1, 0), n.sij(1, 1)
+	m := &Material{Freq: n.Freq.DeepCopy(), EpsR: cvf(len(s11)), MuR: cvf(len(s11))}
+	kc := 2 * math.Pi * fc / c0
+	nrw := ExtractNRW(n, length, fc)
+	eps := complex(1, 0)
+	for k := 0; k < nrw.EpsR.Size; k++ {
+		if v := nrw.EpsR.Get(k); !cmplx.IsNaN(v) && !cmplx.IsInf(v) {
+			eps = v
+			break
+		}
+	}
+	for k := range s11 {
+		w := n.Freq.W.Get(k)
+		lhs := (s21[k] + s12[k] + s11[k] + s22[k]) / 2
+		f := func(eps complex128) complex128 {
+			g0 := cmplx.Sqrt(complex(kc*kc-w*w/(c0*c0), 0))
+			g := cmplx.Sqrt(complex(kc*kc, 0) - eps*complex(w*w/(c0*c0), 0))
+			r := (g0 - g) / (g0 + g)
+			z := cmplx.Exp(-g * complex(length, 0))
+			return (z*(1-r*r)+r*(1-z*z))/(1-z*z*r*r) - lhs
+		}
+
+		for it := 0; it < nistIterations; it++ {
+			h := complex(1e-7*cmplx.Abs(eps), 0)
+			d := f(eps) * h / (f(eps+h) - f(eps))
+			eps -= d
+			if cmplx.Abs(d) < 1e-12*cmplx.Abs(eps) {
+				break
+			}
+		}
+		m.EpsR.Set(k, eps)
+		m.MuR.Set(k, 1)
+	}
+
+	return m
+}
+
+// propagation returns the propagation constant (1/m) in a sample of length
+// (m) from its transmission t.  The phase of t is unwrapped and offset by the
+// multiple of 2*pi that best matches the phase constant found from the group
+// delay, b = (w*b' + sqrt((w*b')^2 - 4*kc^2))/2 with b' = tau/length, which
+// follows from b^2 = w^2*eps*mu/c0^2 - kc^2.  Points where the group delay is
+// not finite are left out of the match.
+func propagation(freq *Frequency, t []complex128, length, fc float64) []complex128 {
+	phase := make([]float64, len(t))
+	for k, val := range t {
+		phase[k] = -cmplx.Phase(val)
+	}
+	phase = unwrap(phase)
+
+	kc := 2 * math.Pi * fc / c0
+	offset, npts := 0., 0
+	for k := range phase {
+		lo, hi := maxint(k-1, 0), minint(k+1, len(phase)-1)
+		db := (phase[hi] - phase[lo]) / (freq.W.Get(hi) - freq.W.Get(lo)) / length
+		wdb := freq.W.Get(k) * db
+		b := (wdb + math.Sqrt(math.Max(wdb*wdb-4*kc*kc, 0))) / 2
+		if x := (b*length - phase[k]) / (2 * math.Pi); !math.IsNaN(x) && !math.IsInf(x, 0) {
+			offset += x
+			npts++
+		}
+	}
+	if npts > 0 {
+		offset = 2 * math.Pi * math.Round(offset/float64(npts))
+	}
+
+	g := make([]complex128, len(t))
+	for k := range t {
+		g[k] = complex(-math.Log(cmplx.Abs(t[k])), phase[k]+offset) / complex(length, 0)
+	}
+	return g
+}
@@ -0,0 +1,71 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// sample is a material filling a fixture with cutoff frequency fc, with its
+// wave impedance relative to the empty fixture scaled to 50 ohms
+type sample struct {
+	eps, mu complex128
+	fc      float64
+}
+
+func (s sample) gamma(w float64, eps, mu complex128) complex128 {
+	kc := 2 * math.Pi * s.fc / c0
+	return cmplx.Sqrt(complex(kc*kc, 0) - eps*mu*complex(w*w/(c0*c0), 0))
+}
+
+func (s sample) Zc(w float64) complex128 {
+	return 50 * s.mu * s.gamma(w, 1, 1) / s.gamma(w, s.eps, s.mu)
+}
+
+func (s sample) Gamma(w float64) complex128 {
+	return s.gamma(w, s.eps, s.mu)
+}
+
+func TestExtractMaterial(t *testing.T) {
+	coax := NewFrequencySweep(0.1, 18, 180, "ghz", Lin)
+	wr90 := NewFrequencySweep(8.2, 12.4, 101, "ghz", Lin)
+	tests := []struct {
+		name   string
+		freq   *Frequency
+		s      sample
+		length float64
+		nrw    bool
+	}{
+		{"coax magnetic", coax, sample{eps: 4 - 0.05i, mu: 2 - 0.1i}, 4e-3, true},
+		{"coax matched", coax, sample{eps: 3, mu: 3}, 4e-3, true},
+		{"coax dielectric", coax, sample{eps: 4 - 0.05i, mu: 1}, 10e-3, false},
+		{"waveguide magnetic", wr90, sample{eps: 2.2 - 0.01i, mu: 1.5 - 0.02i, fc: 6.557e9}, 5e-3, true},
+		{"waveguide dielectric", wr90, sample{eps: 2.2 - 0.01i, mu: 1, fc: 6.557e9}, 20e-3, false},
+	}
+
+	for _, test := range tests {
+		net := Line(test.s, test.freq, test.length, 50)
+		if test.s.eps == test.s.mu {
+			// matched to the fixture, with no reflection at all
+			for _, d := range net.Data {
+				d.Set(0, 0, 0)
+				d.Set(1, 1, 0)
+			}
+		}
+
+		var m *Material
+		if test.nrw {
+			m = ExtractNRW(net, test.length, test.s.fc)
+		} else {
+			m = ExtractNIST(net, test.length, test.s.fc)
+		}
+		for k := 0; k < test.freq.NPts; k++ {
+			if eps := m.EpsR.Get(k); cmplx.IsNaN(eps) || cmplx.Abs(eps-test.s.eps) > 1e-6 {
+				t.Errorf("%v eps doesn't match at %v: got %v want %v\n", test.name, test.freq.Freq.Get(k), eps, test.s.eps)
+			}
+			if mu := m.MuR.Get(k); cmplx.IsNaN(mu) || cmplx.Abs(mu-test.s.mu) > 1e-6 {
+				t.Errorf("%v mu doesn't match at %v: got %v want %v\n", test.name, test.freq.Freq.Get(k), mu, test.s.mu)
+			}
+		}
+	}
+}
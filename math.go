@@ -1,8 +1,11 @@
 package gorf
 
 import (
+	"math"
+	"math/cmplx"
 	"strconv"
 
+	"github.com/whipstein/golinalg/goblas"
 	"github.com/whipstein/golinalg/golapack"
 	"github.com/whipstein/golinalg/mat"
 )
@@ -123,3 +126,343 @@ func cmatInv(m *mat.CMatrix) *mat.CMatrix {
 
 	return m
 }
+
+// lstsq returns the least squares solution x of a*x = b
+func lstsq(a *mat.Matrix, b *mat.Vector) *mat.Vector {
+	m, n := a.Rows, a.Cols
+	acol := a.DeepCopy()
+	acol.ToColMajor()
+	bcol := mf(maxint(m, n), 1, mat.NewMatOptsCol())
+	for i := 0; i < m; i++ {
+		bcol.Set(i, 0, b.Get(i))
+	}
+	lwork := maxint(1, minint(m, n)+maxint(minint(m, n), 1)*64)
+	work := vf(lwork)
+
+	if info, err := golapack.Dgels(mat.NoTrans, m, n, 1, acol, bcol, work, lwork); err != nil || info != 0 {
+		panic("golapack.Dgels error: " + strconv.Itoa(info))
+	}
+
+	x := vf(n)
+	for i := 0; i < n; i++ {
+		x.Set(i, bcol.Get(i, 0))
+	}
+	return x
+}
+
+// qrR returns the upper triangular factor R, min(m, n) x n, of the QR
+// factorization of a
+func qrR(a *mat.Matrix) *mat.Matrix {
+	m, n := a.Rows, a.Cols
+	acol := a.DeepCopy()
+	acol.ToColMajor()
+	tau := vf(minint(m, n))
+	lwork := maxint(1, n*64)
+	work := vf(lwork)
+
+	if err := golapack.Dgeqrf(m, n, acol, tau, work, lwork); err != nil {
+		panic(err)
+	}
+
+	r := mf(minint(m, n), n, opts)
+	for i := 0; i < r.Rows; i++ {
+		for j := i; j < n; j++ {
+			r.Set(i, j, acol.Get(i, j))
+		}
+	}
+	return r
+}
+
+// clstsq returns the least squares solution x of a*x = b
+func clstsq(a *mat.CMatrix, b *mat.CVector) *mat.CVector {
+	m, n := a.Rows, a.Cols
+	acol := a.DeepCopy()
+	acol.ToColMajor()
+	bcol := cmf(maxint(m, n), 1, mat.NewMatOptsCol())
+	for i := 0; i < m; i++ {
+		bcol.Set(i, 0, b.Get(i))
+	}
+	lwork := maxint(1, minint(m, n)+maxint(minint(m, n), 1)*64)
+	work := cvf(lwork)
+
+	if info, err := golapack.Zgels(mat.NoTrans, m, n, 1, acol, bcol, work, lwork); err != nil || info != 0 {
+		panic("golapack.Zgels error: " + strconv.Itoa(info))
+	}
+
+	x := cvf(n)
+	for i := 0; i < n; i++ {
+		x.Set(i, bcol.Get(i, 0))
+	}
+	return x
+}
+
+// eig returns the eigenvalues of the real square matrix a
+func eig(a *mat.Matrix) []complex128 {
+	n := a.Rows
+	acol := a.DeepCopy()
+	acol.ToColMajor()
+	wr, wi := vf(n), vf(n)
+	dummy := mf(n, n, mat.NewMatOptsCol())
+	lwork := 8 * maxint(n, 1)
+	work := vf(lwork)
+
+	if info, err := golapack.Dgeev('N', 'N', n, acol, wr, wi, dummy, dummy, work, lwork); err != nil || info != 0 {
+		panic("golapack.Dgeev error: " + strconv.Itoa(info))
+	}
+
+	lambda := make([]complex128, n)
+	for i := range lambda {
+		lambda[i] = complex(wr.Get(i), wi.Get(i))
+	}
+	return lambda
+}
+
+// ceig returns the eigenvalues of the complex square matrix a, from the
+// same zgeev routine as Passivity
+func ceig(a *mat.CMatrix) []complex128 {
+	n := a.Rows
+	acol := a.DeepCopy()
+	acol.ToColMajor()
+	lambda := cvf(n)
+	dummy := cvf(n * n)
+	lwork := 4 * maxint(n, 1)
+	work := cvf(lwork)
+	rwork := vf(2 * maxint(n, 1))
+
+	var info int
+	_Zgeev('N', 'N', n, acol.Data, n, lambda.Data, dummy.Data, n, dummy.Data, n, work.Data, lwork, rwork.Data, &info)
+	if info != 0 {
+		panic("zgeev error: " + strconv.Itoa(info))
+	}
+	return lambda.Data
+}
+
+// csvd returns the singular values of a in descending order, the left
+// singular vectors u and the conjugate transpose of the right singular
+// vectors vh
+func csvd(a *mat.CMatrix) ([]float64, *mat.CMatrix, *mat.CMatrix) {
+	m, n := a.Rows, a.Cols
+	acol := a.DeepCopy()
+	acol.ToColMajor()
+	sv := vf(minint(m, n))
+	u := cmf(m, m, mat.NewMatOptsCol())
+	vh := cmf(n, n, mat.NewMatOptsCol())
+	lwork := 4 * maxint(m, n, 1)
+	work := cvf(lwork)
+	rwork := vf(5 * maxint(minint(m, n), 1))
+
+	if info, err := golapack.Zgesvd('A', 'A', m, n, acol, sv, u, vh, work, lwork, rwork); err != nil || info != 0 {
+		panic("golapack.Zgesvd error: " + strconv.Itoa(info))
+	}
+	return sv.Data, u, vh
+}
+
+// cheig returns the eigenvalues of the Hermitian matrix a in ascending order
+// and the corresponding eigenvectors as the columns of z
+func cheig(a *mat.CMatrix) ([]float64, *mat.CMatrix) {
+	n := a.Rows
+	z := a.DeepCopy()
+	z.ToColMajor()
+	w := vf(n)
+	lwork := 4 * maxint(n, 1)
+	work := cvf(lwork)
+	rwork := vf(3 * maxint(n, 1))
+
+	if info, err := golapack.Zheev('V', mat.Upper, n, z, w, work, lwork, rwork); err != nil || info != 0 {
+		panic("golapack.Zheev error: " + strconv.Itoa(info))
+	}
+	return w.Data, z
+}
+
+// mmul returns the product a*b
+func mmul(a, b *mat.Matrix) *mat.Matrix {
+	c := mf(a.Rows, b.Cols, opts)
+	if err := goblas.Dgemm(mat.NoTrans, mat.NoTrans, a.Rows, b.Cols, a.Cols, 1, a, b, 0, c); err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// cmmul returns a*b
+func cmmul(a, b *mat.CMatrix) *mat.CMatrix {
+	c := cmf(a.Rows, b.Cols, opts)
+	if err := goblas.Zgemm(mat.NoTrans, mat.NoTrans, a.Rows, b.Cols, a.Cols, 1, a, b, 0, c); err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// transpose returns a**T
+func transpose(a *mat.Matrix) *mat.Matrix {
+	t := mf(a.Cols, a.Rows, opts)
+	for i := 0; i < a.Rows; i++ {
+		for j := 0; j < a.Cols; j++ {
+			t.Set(j, i, a.Get(i, j))
+		}
+	}
+	return t
+}
+
+// interp1 linearly interpolates y(x) at xi, holding the end values outside
+// the range of x.  x must be ascending.
+func interp1(x, y []float64, xi float64) float64 {
+	n := len(x)
+	if n == 1 || xi <= x[0] {
+		return y[0]
+	} else if xi >= x[n-1] {
+		return y[n-1]
+	}
+
+	i := 1
+	for x[i] < xi {
+		i++
+	}
+	if x[i] == x[i-1] {
+		return y[i]
+	}
+	return y[i-1] + (y[i]-y[i-1])*(xi-x[i-1])/(x[i]-x[i-1])
+}
+
+// unwrap removes the 2*pi jumps from a phase sequence in radians
+func unwrap(p []float64) []float64 {
+	out := make([]float64, len(p))
+	if len(p) == 0 {
+		return out
+	}
+
+	offset := 0.
+	out[0] = p[0]
+	for i := 1; i < len(p); i++ {
+		d := p[i] - p[i-1]
+		if d > math.Pi {
+			offset -= 2 * math.Pi * math.Ceil((d-math.Pi)/(2*math.Pi))
+		} else if d < -math.Pi {
+			offset += 2 * math.Pi * math.Ceil((-d-math.Pi)/(2*math.Pi))
+		}
+		out[i] = p[i] + offset
+	}
+	return out
+}
+
+// polyfit returns the coefficients c[0] + c[1]*x + ... + c[deg]*x**deg of the
+// least squares polynomial through (x, y)
+func polyfit(x, y []float64, deg int) []float64 {
+	a := mf(len(x), deg+1, opts)
+	b := vf(len(x))
+	for i := range x {
+		p := 1.
+		for j := 0; j <= deg; j++ {
+			a.Set(i, j, p)
+			p *= x[i]
+		}
+		b.Set(i, y[i])
+	}
+	return lstsq(a, b).Data
+}
+
+// polyval evaluates the polynomial with coefficients c at x
+func polyval(c []float64, x float64) float64 {
+	y := 0.
+	for i := len(c) - 1; i >= 0; i-- {
+		y = y*x + c[i]
+	}
+	return y
+}
+
+// fft returns the discrete Fourier transform of x, or the inverse transform
+// scaled by 1/N when inv is true.  Lengths that are not a power of 2 use
+// Bluestein's algorithm.
+func fft(x []complex128, inv bool) []complex128 {
+	n := len(x)
+	sign := -1.
+	if inv {
+		sign = 1.
+	}
+
+	var out []complex128
+	if n&(n-1) == 0 {
+		out = fftRadix2(x, sign)
+	} else {
+		out = fftBluestein(x, sign)
+	}
+
+	if inv {
+		for i := range out {
+			out[i] /= complex(float64(n), 0)
+		}
+	}
+	return out
+}
+
+// fftBluestein returns the unscaled transform of x of any length as the
+// convolution, by power of 2 transforms, of x*w with conj(w), where
+// w[k] = exp(sign*j*pi*k^2/n)
+func fftBluestein(x []complex128, sign float64) []complex128 {
+	n := len(x)
+	m := 1
+	for m < 2*n-1 {
+		m <<= 1
+	}
+
+	w := make([]complex128, n)
+	for k := range w {
+		w[k] = cmplx.Exp(complex(0, sign*math.Pi*float64(k*k%(2*n))/float64(n)))
+	}
+	a, b := make([]complex128, m), make([]complex128, m)
+	for k := range x {
+		a[k] = x[k] * w[k]
+		b[k] = cmplx.Conj(w[k])
+		if k > 0 {
+			b[m-k] = b[k]
+		}
+	}
+
+	a, b = fftRadix2(a, -1), fftRadix2(b, -1)
+	for k := range a {
+		a[k] *= b[k]
+	}
+	a = fftRadix2(a, 1)
+
+	out := make([]complex128, n)
+	for k := range out {
+		out[k] = w[k] * a[k] / complex(float64(m), 0)
+	}
+	return out
+}
+
+func fftRadix2(x []complex128, sign float64) []complex128 {
+	n := len(x)
+	if n <= 1 {
+		return append([]complex128{}, x...)
+	}
+
+	even := make([]complex128, n/2)
+	odd := make([]complex128, n/2)
+	for i := 0; i < n/2; i++ {
+		even[i] = x[2*i]
+		odd[i] = x[2*i+1]
+	}
+	even = fftRadix2(even, sign)
+	odd = fftRadix2(odd, sign)
+
+	out := make([]complex128, n)
+	for k := 0; k < n/2; k++ {
+		t := cmplx.Exp(complex(0, sign*2*math.Pi*float64(k)/float64(n))) * odd[k]
+		out[k] = even[k] + t
+		out[k+n/2] = even[k] - t
+	}
+	return out
+}
+
+// besselI0 returns the modified Bessel function of the first kind of order 0
+func besselI0(x float64) float64 {
+	sum, term := 1., 1.
+	for k := 1; k < 500; k++ {
+		term *= (x / (2 * float64(k))) * (x / (2 * float64(k)))
+		sum += term
+		if term < sum*epsf64 {
+			break
+		}
+	}
+	return sum
+}
@@ -0,0 +1,298 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+const (
+	mu0  = 4e-7 * math.Pi // permeability of vacuum (H/m)
+	eps0 = 1 / (mu0 * c0 * c0)
+	eta0 = mu0 * c0
+)
+
+// Media is a transmission line medium described by its characteristic
+// impedance and propagation constant (1/m) at angular frequency w (rad/s)
+type Media interface {
+	Zc(w float64) complex128
+	Gamma(w float64) complex128
+}
+
+// Line returns a section of line of length (m) in medium m on freq as a
+// 2-port in S with real reference impedance z0
+func Line(m Media, freq *Frequency, length, z0 float64) *Network {
+	net := lumpedNetwork(freq, z0)
+	for k := 0; k < freq.NPts; k++ {
+		w := freq.W.Get(k)
+		zc, gl := m.Zc(w), m.Gamma(w)*complex(length, 0)
+		r := complex(z0, 0)
+		sh, ch := cmplx.Sinh(gl), cmplx.Cosh(gl)
+		d := 2*zc*r*ch + (zc*zc+r*r)*sh
+
+		s := cmf(2, 2, opts)
+		s.Set(0, 0, (zc*zc-r*r)*sh/d)
+		s.Set(0, 1, 2*zc*r/d)
+		s.Set(1, 0, 2*zc*r/d)
+		s.Set(1, 1, (zc*zc-r*r)*sh/d)
+		net.Data = append(net.Data, s)
+	}
+	return net
+}
+
+// Open returns the 1-port of an open circuit at the end of a line of length
+// (m) in medium m, in S with real reference impedance z0
+func Open(m Media, freq *Frequency, length, z0 float64) *Network {
+	return termination(m, freq, length, z0, true)
+}
+
+// Short returns the 1-port of a short circuit at the end of a line of length
+// (m) in medium m, in S with real reference impedance z0
+func Short(m Media, freq *Frequency, length, z0 float64) *Network {
+	return termination(m, freq, length, z0, false)
+}
+
+func termination(m Media, freq *Frequency, length, z0 float64, open bool) *Network {
+	net := NewNetwork()
+	net.SetPorts(1)
+	net.Z0.SetReAll(z0)
+	net.Param = S
+	net.Freq = freq.DeepCopy()
+
+	for k := 0; k < freq.NPts; k++ {
+		w := freq.W.Get(k)
+		zc, th := m.Zc(w), cmplx.Tanh(m.Gamma(w)*complex(length, 0))
+		r := complex(z0, 0)
+
+		s := cmf(1, 1, opts)
+		if open {
+			s.Set(0, 0, (zc-r*th)/(zc+r*th))
+		} else {
+			s.Set(0, 0, (zc*th-r)/(zc*th+r))
+		}
+		net.Data = append(net.Data, s)
+	}
+	return net
+}
+
+// Stub returns a shunt stub of length (m) in medium m, open or shorted at its
+// end, as a 2-port in S with real reference impedance z0
+func Stub(m Media, freq *Frequency, length float64, open bool, z0 float64) *Network {
+	return ShuntAdmittance(freq, func(w float64) complex128 {
+		th := cmplx.Tanh(m.Gamma(w) * complex(length, 0))
+		if open {
+			return th / m.Zc(w)
+		}
+		return 1 / (m.Zc(w) * th)
+	}, z0)
+}
+
+// TEMLine is an ideal TEM line of characteristic impedance Z0 and effective
+// permittivity EpsEff.  AlphaC is the conductor loss (Np/m) at 1 GHz, which
+// scales with the square root of frequency, and TanD the dielectric loss
+// tangent.
+type TEMLine struct {
+	Z0, EpsEff, AlphaC, TanD float64
+}
+
+func (l TEMLine) Zc(w float64) complex128 {
+	return complex(l.Z0, 0)
+}
+
+func (l TEMLine) Gamma(w float64) complex128 {
+	beta := w * math.Sqrt(l.EpsEff) / c0
+	alpha := l.AlphaC*math.Sqrt(w/(2*math.Pi*1e9)) + beta*l.TanD/2
+	return complex(alpha, beta)
+}
+
+// Coax is a coaxial line with inner conductor diameter Din and outer
+// conductor inner diameter Dout (m), filled with a dielectric of relative
+// permittivity EpsR and loss tangent TanD.  Conductors of conductivity Sigma
+// (S/m) have skin effect loss; Sigma = 0 is lossless.
+type Coax struct {
+	Din, Dout, EpsR, TanD, Sigma float64
+}
+
+// rlgc returns the per unit length series impedance and shunt admittance
+func (c Coax) rlgc(w float64) (complex128, complex128) {
+	ln := math.Log(c.Dout / c.Din)
+	l := mu0 / (2 * math.Pi) * ln
+	cp := 2 * math.Pi * eps0 * c.EpsR / ln
+	r := surfaceResistance(w, c.Sigma) / math.Pi * (1/c.Din + 1/c.Dout)
+	return complex(r, w*l), complex(w*cp*c.TanD, w*cp)
+}
+
+func (c Coax) Zc(w float64) complex128 {
+	if w == 0 {
+		return complex(eta0/(2*math.Pi*math.Sqrt(c.EpsR))*math.Log(c.Dout/c.Din), 0)
+	}
+	z, y := c.rlgc(w)
+	return cmplx.Sqrt(z / y)
+}
+
+func (c Coax) Gamma(w float64) complex128 {
+	z, y := c.rlgc(w)
+	return cmplx.Sqrt(z * y)
+}
+
+// Microstrip is a strip of width W and thickness T on a substrate of height H
+// (m), relative permittivity EpsR and loss tangent TanD, with conductors of
+// conductivity Sigma (S/m; 0 is lossless).  The impedance and effective
+// permittivity follow Hammerstad and Jensen, including the strip thickness
+// correction, with Getsinger's dispersion model.
+type Microstrip struct {
+	W, H, T, EpsR, TanD, Sigma float64
+}
+
+// static returns the quasi-static characteristic impedance and effective
+// permittivity
+func (m Microstrip) static() (float64, float64) {
+	u := m.W / m.H
+	z01 := func(u float64) float64 {
+		f := 6 + (2*math.Pi-6)*math.Exp(-math.Pow(30.666/u, 0.7528))
+		return eta0 / (2 * math.Pi) * math.Log(f/u+math.Sqrt(1+4/(u*u)))
+	}
+	epsEff := func(u float64) float64 {
+		a := 1 + math.Log((math.Pow(u, 4)+math.Pow(u/52, 2))/(math.Pow(u, 4)+0.432))/49 + math.Log(1+math.Pow(u/18.1, 3))/18.7
+		b := 0.564 * math.Pow((m.EpsR-0.9)/(m.EpsR+3), 0.053)
+		return (m.EpsR+1)/2 + (m.EpsR-1)/2*math.Pow(1+10/u, -a*b)
+	}
+
+	if m.T == 0 {
+		e := epsEff(u)
+		return z01(u) / math.Sqrt(e), e
+	}
+
+	t := m.T / m.H
+	du1 := t / math.Pi * math.Log(1+4*math.E/(t*math.Pow(1/math.Tanh(math.Sqrt(6.517*u)), 2)))
+	dur := (1 + 1/math.Cosh(math.Sqrt(m.EpsR-1))) * du1 / 2
+	u1, ur := u+du1, u+dur
+	e := epsEff(ur) * math.Pow(z01(u1)/z01(ur), 2)
+	return z01(ur) / math.Sqrt(epsEff(ur)), e
+}
+
+// dispersion returns the characteristic impedance and effective permittivity
+// at w
+func (m Microstrip) dispersion(w float64) (float64, float64) {
+	z0, e0 := m.static()
+	fp := z0 / (2 * mu0 * m.H)
+	g := 0.6 + 0.009*z0
+	f := w / (2 * math.Pi)
+	e := m.EpsR - (m.EpsR-e0)/(1+g*math.Pow(f/fp, 2))
+	if e0 == 1 {
+		return z0, e
+	}
+	return z0 * math.Sqrt(e0/e) * (e - 1) / (e0 - 1), e
+}
+
+func (m Microstrip) Zc(w float64) complex128 {
+	z, _ := m.dispersion(w)
+	return complex(z, 0)
+}
+
+func (m Microstrip) Gamma(w float64) complex128 {
+	z, e := m.dispersion(w)
+	k0 := w / c0
+	alpha := surfaceResistance(w, m.Sigma) / (z * m.W)
+	if m.EpsR != 1 {
+		alpha += k0 * m.EpsR * (e - 1) * m.TanD / (2 * math.Sqrt(e) * (m.EpsR - 1))
+	}
+	return complex(alpha, k0*math.Sqrt(e))
+}
+
+// CPW is a coplanar waveguide with centre strip width W and gaps S on a
+// substrate of height H (m), relative permittivity EpsR and loss tangent
+// TanD, without a backside ground.  The impedance and effective permittivity
+// come from conformal mapping.  Conductor loss, for conductivity Sigma (S/m),
+// follows Owyang and Wu and needs the strip thickness T; it is ignored for
+// T = 0.
+type CPW struct {
+	W, S, H, T, EpsR, TanD, Sigma float64
+}
+
+// static returns the characteristic impedance and effective permittivity
+func (c CPW) static() (float64, float64) {
+	k0 := c.W / (c.W + 2*c.S)
+	k1 := math.Sinh(math.Pi*c.W/(4*c.H)) / math.Sinh(math.Pi*(c.W+2*c.S)/(4*c.H))
+	e := 1 + (c.EpsR-1)/2*ellipticRatio(k1)/ellipticRatio(k0)
+	return eta0 / (4 * math.Sqrt(e) * ellipticRatio(k0)), e
+}
+
+func (c CPW) Zc(w float64) complex128 {
+	z, _ := c.static()
+	return complex(z, 0)
+}
+
+func (c CPW) Gamma(w float64) complex128 {
+	_, e := c.static()
+	k0 := w / c0
+	alpha := 0.
+	if c.EpsR != 1 {
+		alpha += k0 * c.EpsR * (e - 1) * c.TanD / (2 * math.Sqrt(e) * (c.EpsR - 1))
+	}
+	if c.T > 0 && c.Sigma > 0 {
+		a, b := c.W/2, c.W/2+c.S
+		k := a / b
+		kk := ellipticK(k)
+		kp := ellipticK(math.Sqrt(1 - k*k))
+		edge := func(x float64) float64 {
+			return (math.Pi + math.Log(4*math.Pi*x*(1-k)/(c.T*(1+k)))) / x
+		}
+		alpha += surfaceResistance(w, c.Sigma) * math.Sqrt(e) / (480 * math.Pi * kk * kp * (1 - k*k)) * (edge(a) + edge(b))
+	}
+	return complex(alpha, k0*math.Sqrt(e))
+}
+
+// Stripline is a centred strip of width W and thickness T between grounds
+// spaced B (m) apart, in a dielectric of relative permittivity EpsR and loss
+// tangent TanD.  The impedance is the exact zero thickness result; T only
+// enters the conductor loss, for conductivity Sigma (S/m), which follows
+// Wheeler's incremental inductance rule and is ignored for T = 0.
+type Stripline struct {
+	W, B, T, EpsR, TanD, Sigma float64
+}
+
+func (s Stripline) Zc(w float64) complex128 {
+	k := 1 / math.Cosh(math.Pi*s.W/(2*s.B))
+	return complex(eta0/(4*math.Sqrt(s.EpsR))*ellipticRatio(k), 0)
+}
+
+func (s Stripline) Gamma(w float64) complex128 {
+	beta := w * math.Sqrt(s.EpsR) / c0
+	alpha := beta * s.TanD / 2
+	if s.T > 0 && s.Sigma > 0 {
+		z := real(s.Zc(w))
+		bt := s.B - s.T
+		if math.Sqrt(s.EpsR)*z < 120 {
+			a := 1 + 2*s.W/bt + (s.B+s.T)/(math.Pi*bt)*math.Log((2*s.B-s.T)/s.T)
+			alpha += 2.7e-3 * surfaceResistance(w, s.Sigma) * s.EpsR * z / (30 * math.Pi * bt) * a
+		} else {
+			b := 1 + s.B/(0.5*s.W+0.7*s.T)*(0.5+0.414*s.T/s.W+math.Log(4*math.Pi*s.W/s.T)/(2*math.Pi))
+			alpha += 0.16 * surfaceResistance(w, s.Sigma) / (z * s.B) * b
+		}
+	}
+	return complex(alpha, beta)
+}
+
+// surfaceResistance returns the skin effect surface resistance of a
+// conductor of conductivity sigma (S/m), 0 for sigma = 0
+func surfaceResistance(w, sigma float64) float64 {
+	if sigma == 0 {
+		return 0
+	}
+	return math.Sqrt(w * mu0 / (2 * sigma))
+}
+
+// ellipticK returns the complete elliptic integral of the first kind of
+// modulus k
+func ellipticK(k float64) float64 {
+	a, b := 1., math.Sqrt(1-k*k)
+	for math.Abs(a-b) > 1e-15*a {
+		a, b = (a+b)/2, math.Sqrt(a*b)
+	}
+	return math.Pi / (2 * a)
+}
+
+// ellipticRatio returns K(k)/K(k'), k' = sqrt(1 - k**2)
+func ellipticRatio(k float64) float64 {
+	return ellipticK(k) / ellipticK(math.Sqrt(1-k*k))
+}
@@ -0,0 +1,74 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestLine(t *testing.T) {
+	freq := NewFrequencySweep(1, 1, 1, "ghz", Lin)
+	w := freq.W.Get(0)
+	quarter := c0 / 1e9 / 4
+
+	// quarter wave transformer of 100 ohms presents 200 ohms to a 50 ohm load
+	tl := TEMLine{Z0: 100, EpsEff: 1}
+	if s := Line(tl, freq, quarter, 50).Data[0]; cmplx.Abs(s.Get(0, 0)-0.6) > eps || cmplx.Abs(s.Get(1, 0)+0.8i) > eps {
+		t.Errorf("Quarter wave line doesn't match: got %v %v want %v %v\n", s.Get(0, 0), s.Get(1, 0), 0.6, -0.8i)
+	}
+	if s := Open(tl, freq, quarter, 50).Data[0].Get(0, 0); cmplx.Abs(s+1) > eps {
+		t.Errorf("Quarter wave open doesn't match: got %v want -1\n", s)
+	}
+	if s := Short(tl, freq, 0, 50).Data[0].Get(0, 0); cmplx.Abs(s+1) > eps {
+		t.Errorf("Short doesn't match: got %v want -1\n", s)
+	}
+	if s := Stub(tl, freq, quarter, true, 50).Data[0]; cmplx.Abs(s.Get(0, 0)+1) > eps || cmplx.Abs(s.Get(1, 0)) > eps {
+		t.Errorf("Quarter wave open stub doesn't match: got %v %v want -1 0\n", s.Get(0, 0), s.Get(1, 0))
+	}
+
+	lossy := TEMLine{Z0: 50, EpsEff: 4, TanD: 0.01}
+	res := math.Exp(-w * 2 / c0 * 0.01 / 2 * 0.1)
+	if s := Line(lossy, freq, 0.1, 50).Data[0]; math.Abs(cmplx.Abs(s.Get(1, 0))-res) > eps || cmplx.Abs(s.Get(0, 0)) > eps {
+		t.Errorf("Lossy line doesn't match: got %v want %v\n", cmplx.Abs(s.Get(1, 0)), res)
+	}
+}
+
+func TestMedia(t *testing.T) {
+	w := 2 * math.Pi * 1e9
+
+	coax := Coax{Din: 1e-3, Dout: 1e-3 * math.Exp(50*2*math.Pi*math.Sqrt(2.1)/eta0), EpsR: 2.1}
+	if z := coax.Zc(w); cmplx.Abs(z-50) > eps {
+		t.Errorf("Coax impedance doesn't match: got %v want 50\n", z)
+	}
+	coax.Sigma = 5.8e7
+	if a := real(coax.Gamma(w)); a <= 0 {
+		t.Errorf("Coax conductor loss not positive: got %v\n", a)
+	}
+
+	// Pozar's closed form analysis, accurate to about 1 percent
+	ms := Microstrip{W: 2e-3, H: 1e-3, EpsR: 4.4}
+	e := 2.7 + 1.7/math.Sqrt(1+6)
+	res := 120 * math.Pi / (math.Sqrt(e) * (2 + 1.393 + 0.667*math.Log(2+1.444)))
+	if z := real(ms.Zc(0)); math.Abs(z-res) > 0.01*res {
+		t.Errorf("Microstrip impedance doesn't match: got %v want %v\n", z, res)
+	}
+	if e0, e1 := math.Pow(imag(ms.Gamma(w))/(w/c0), 2), math.Pow(imag(ms.Gamma(100*w))/(100*w/c0), 2); math.Abs(e0-e) > 0.01*e || e1 <= e0 || e1 >= 4.4 {
+		t.Errorf("Microstrip dispersion doesn't match: got %v at 1 GHz and %v at 100 GHz\n", e0, e1)
+	}
+
+	cpw := CPW{W: 1e-3, S: 0.5e-3, H: 1, EpsR: 1}
+	if z, res := real(cpw.Zc(w)), eta0/4*2.156515647499643/1.685750354812596; math.Abs(z-res) > eps*res {
+		t.Errorf("CPW impedance doesn't match: got %v want %v\n", z, res)
+	}
+	cpw.EpsR = 9.9
+	if e := math.Pow(imag(cpw.Gamma(w))/(w/c0), 2); math.Abs(e-5.45) > 1e-6 {
+		t.Errorf("CPW effective permittivity doesn't match: got %v want %v\n", e, 5.45)
+	}
+
+	// Pozar's closed form for a zero thickness strip
+	sl := Stripline{W: 1e-3, B: 1e-3, EpsR: 2.2}
+	res = 30 * math.Pi / math.Sqrt(2.2) / (1 + 0.441)
+	if z := real(sl.Zc(w)); math.Abs(z-res) > 0.01*res {
+		t.Errorf("Stripline impedance doesn't match: got %v want %v\n", z, res)
+	}
+}
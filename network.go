@@ -171,6 +171,7 @@ func (n *Network) ReadTouchstone(f string) *Network {
 			continue
 		} else if noisePattern.Match(bytes.ToLower(line)) {
 			n.Noise = NewNoiseNetwork()
+			n.Noise.Freq.Unit = n.Freq.Unit
 			n.Noise.ReadTouchstone(r)
 			break
 		} else if line[0] == '!' {
@@ -558,3 +559,52 @@ func (n *Network) Z() []*mat.CMatrix {
 
 	return net.Data
 }
+
+// Cascade connects the networks in sequence, the second half of the ports of
+// each to the first half of the next, and returns the result in S
+func Cascade(nets ...*Network) *Network {
+	if len(nets) == 0 {
+		panic("no networks to cascade")
+	}
+	for _, net := range nets {
+		if net.NPorts%2 != 0 || net.NPorts != nets[0].NPorts {
+			panic("cascaded networks must have the same even number of ports")
+		} else if net.Freq.NPts != nets[0].Freq.NPts {
+			panic("cascaded network frequencies differ")
+		}
+	}
+
+	out := nets[0].DeepCopy()
+	out.Param = S
+	out.Data = nets[0].T()
+	for _, net := range nets[1:] {
+		t := net.T()
+		for k := range out.Data {
+			out.Data[k] = cmmul(out.Data[k], t[k])
+		}
+	}
+	for k := range out.Data {
+		TtoS(out.Data[k], out.Z0)
+	}
+	return out
+}
+
+// Crop returns a copy of the network with only the frequencies from start to
+// stop (Hz) inclusive
+func (n *Network) Crop(start, stop float64) *Network {
+	out := n.DeepCopy()
+	out.Freq = NewFrequency()
+	out.Freq.Unit = n.Freq.Unit
+	out.Freq.SweepType = n.Freq.SweepType
+	out.Data = make([]*mat.CMatrix, 0)
+	for k := 0; k < n.Freq.NPts; k++ {
+		if f := n.Freq.Freq.Get(k); f >= start && f <= stop {
+			out.Freq.Append(n.Freq.FreqScaled.Get(k))
+			out.Data = append(out.Data, n.Data[k].DeepCopy())
+		}
+	}
+	if out.Freq.NPts == 0 {
+		panic("no frequencies in the cropped band")
+	}
+	return out
+}
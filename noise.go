@@ -0,0 +1,163 @@
+package gorf
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+const (
+	boltzmann = 1.380649e-23
+	t0        = 290.
+)
+
+// Interpolate resamples the noise parameters onto freq.  Gopt is interpolated
+// in magnitude and unwrapped phase, NFmin as a linear noise factor and Rn
+// linearly.  Frequencies outside the measured band hold the nearest end value.
+func (n *NoiseNetwork) Interpolate(freq *Frequency) *NoiseNetwork {
+	if n.Freq.NPts == 0 {
+		panic("noise network has no data to interpolate")
+	}
+
+	out := NewNoiseNetwork()
+	out.Freq = freq.DeepCopy()
+
+	for _, f := range freq.Freq.Data {
+		nfmin, gopt, rn := n.at(f)
+		out.NFmin.Append(nfmin)
+		out.Gopt.Append(gopt)
+		out.Rn.Append(rn)
+	}
+
+	return out
+}
+
+// at returns NFmin (dB), Gopt and Rn at frequency f in Hz
+func (n *NoiseNetwork) at(f float64) (float64, complex128, float64) {
+	x := n.Freq.Freq.Data[:n.Freq.NPts]
+	fmin := make([]float64, len(x))
+	mag := make([]float64, len(x))
+	ang := make([]float64, len(x))
+
+	for i := range x {
+		fmin[i] = math.Pow(10, n.NFmin.Get(i)/10)
+		mag[i], ang[i] = cmplx.Polar(n.Gopt.Get(i))
+	}
+	ang = unwrap(ang)
+
+	nfmin := 10 * math.Log10(interp1(x, fmin, f))
+	gopt := cmplx.Rect(interp1(x, mag, f), interp1(x, ang, f))
+	rn := interp1(x, n.Rn.Data[:len(x)], f)
+
+	return nfmin, gopt, rn
+}
+
+// NoiseAt returns NFmin (dB), Gopt and Rn at frequency f in Hz, interpolated
+// from the noise data read with the network
+func (n *Network) NoiseAt(f float64) (nfmin float64, gopt complex128, rn float64) {
+	if n.Noise == nil {
+		panic("network does not contain noise parameters")
+	}
+
+	return n.Noise.at(f)
+}
+
+// ExtractNoise fits NFmin, Gopt and Rn at each frequency of freq from noise
+// figures nf (dB) measured with source reflection coefficients gs, using
+// Lane's linearised least squares form
+//
+//	F = A + B*(g + b**2/g) + C/g + D*b/g
+//
+// with ys = g + j*b the source admittance normalized to z0.  Rn is returned
+// normalized to z0 as in touchstone files.  The second return value holds the
+// RMS fit residual in dB at each frequency.
+func ExtractNoise(freq *Frequency, gs []*mat.CVector, nf []*mat.Vector) (*NoiseNetwork, *mat.Vector) {
+	if len(gs) != freq.NPts || len(nf) != freq.NPts {
+		panic("number of noise measurements does not match frequency points")
+	}
+
+	n := NewNoiseNetwork()
+	n.Freq = freq.DeepCopy()
+	residual := vf(freq.NPts)
+
+	for k := 0; k < freq.NPts; k++ {
+		if gs[k].Size != nf[k].Size {
+			panic(fmt.Sprintf("source and noise figure counts differ at %v Hz", freq.Freq.Get(k)))
+		} else if gs[k].Size < 4 {
+			panic(fmt.Sprintf("at least 4 source states needed at %v Hz", freq.Freq.Get(k)))
+		}
+
+		a := mf(gs[k].Size, 4, opts)
+		b := vf(gs[k].Size)
+		for i := 0; i < gs[k].Size; i++ {
+			ys := (1 - gs[k].Get(i)) / (1 + gs[k].Get(i))
+			g, bs := real(ys), imag(ys)
+			a.Set(i, 0, 1)
+			a.Set(i, 1, g+bs*bs/g)
+			a.Set(i, 2, 1/g)
+			a.Set(i, 3, bs/g)
+			b.Set(i, math.Pow(10, nf[k].Get(i)/10))
+		}
+
+		x := lstsq(a, b)
+		rn := x.Get(1)
+		bopt := -x.Get(3) / (2 * rn)
+		gopt2 := x.Get(2)/rn - bopt*bopt
+		if rn <= 0 || gopt2 <= 0 {
+			panic(fmt.Sprintf("nonphysical noise parameters fitted at %v Hz", freq.Freq.Get(k)))
+		}
+		yopt := complex(math.Sqrt(gopt2), bopt)
+		fmin := x.Get(0) + 2*rn*real(yopt)
+
+		n.NFmin.Append(10 * math.Log10(fmin))
+		n.Gopt.Append((1 - yopt) / (1 + yopt))
+		n.Rn.Append(rn)
+
+		sum := 0.
+		for i := 0; i < gs[k].Size; i++ {
+			d := NoiseFigure(n.NFmin.Get(k), n.Gopt.Get(k), rn, gs[k].Get(i)) - nf[k].Get(i)
+			sum += d * d
+		}
+		residual.Set(k, math.Sqrt(sum/float64(gs[k].Size)))
+	}
+
+	return n, residual
+}
+
+// NoiseFigure returns the noise figure (dB) presented by a source reflection
+// coefficient gs to a device with noise parameters nfmin (dB), gopt and rn
+// (normalized)
+func NoiseFigure(nfmin float64, gopt complex128, rn float64, gs complex128) float64 {
+	fmin := math.Pow(10, nfmin/10)
+	d := cmplx.Abs(gs - gopt)
+	f := fmin + 4*rn*d*d/((1-cmplx.Abs(gs)*cmplx.Abs(gs))*math.Pow(cmplx.Abs(1+gopt), 2))
+	return 10 * math.Log10(f)
+}
+
+// YFactorNF returns the noise figure (dB) from a Y-factor measurement with a
+// noise source of excess noise ratio enr (dB), measured y (dB) and cold
+// source temperature tcold in kelvin
+func YFactorNF(enr, y, tcold float64) float64 {
+	enrlin := math.Pow(10, enr/10)
+	ylin := math.Pow(10, y/10)
+	return 10 * math.Log10((enrlin-ylin*(tcold/t0-1))/(ylin-1))
+}
+
+// AvailableGain returns the available power gain of 2-port s when driven
+// from a source with reflection coefficient gs
+func AvailableGain(s *mat.CMatrix, gs complex128) float64 {
+	gout := s.Get(1, 1) + s.Get(0, 1)*s.Get(1, 0)*gs/(1-s.Get(0, 0)*gs)
+	num := math.Pow(cmplx.Abs(s.Get(1, 0)), 2) * (1 - math.Pow(cmplx.Abs(gs), 2))
+	den := math.Pow(cmplx.Abs(1-s.Get(0, 0)*gs), 2) * (1 - math.Pow(cmplx.Abs(gout), 2))
+	return num / den
+}
+
+// ColdSourceNF returns the noise figure (dB) from a cold-source measurement:
+// the noise power nout (W) delivered in bandwidth (Hz) by 2-port s, whose
+// input is terminated at 290 K with reflection coefficient gs.  Receiver
+// noise must already be removed from nout.
+func ColdSourceNF(s *mat.CMatrix, gs complex128, nout, bandwidth float64) float64 {
+	return 10 * math.Log10(nout/(boltzmann*t0*bandwidth*AvailableGain(s, gs)))
+}
@@ -0,0 +1,85 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+func TestNoiseInterpolate(t *testing.T) {
+	net := NewNetwork()
+	net.ReadTouchstone("./data/ntwk_noise.s2p")
+
+	noise := net.Noise.Interpolate(net.Freq)
+	if noise.Freq.NPts != net.Freq.NPts {
+		t.Fatalf("Frequency points don't match: got %v want %v\n", noise.Freq.NPts, net.Freq.NPts)
+	}
+
+	var res = []struct {
+		f, nfmin, mag, ang, rn float64
+	}{
+		{1e9, 0.5, 0.2, 134.27, 0.1159},
+		{1.5e9, 10 * math.Log10((math.Pow(10, 0.05)+math.Pow(10, 0.1))/2), 0.3, 138.27, 0.1159},
+		{2e9, 1., 0.4, 142.27, 0.1159},
+	}
+	for _, val := range res {
+		nfmin, gopt, rn := net.NoiseAt(val.f)
+		if math.Abs(nfmin-val.nfmin) > eps {
+			t.Errorf("NFmin doesn't match: got %v want %v\n", nfmin, val.nfmin)
+		}
+		if math.Abs(cmplx.Abs(gopt)-val.mag) > eps || math.Abs(cmplx.Phase(gopt)*180/math.Pi-val.ang) > eps {
+			t.Errorf("Gopt doesn't match: got %v want %v<%v\n", gopt, val.mag, val.ang)
+		}
+		if math.Abs(rn-val.rn) > eps {
+			t.Errorf("Rn doesn't match: got %v want %v\n", rn, val.rn)
+		}
+	}
+
+	for i, f := range noise.Freq.Freq.Data {
+		nfmin, gopt, rn := net.NoiseAt(f)
+		if noise.NFmin.Get(i) != nfmin || noise.Gopt.Get(i) != gopt || noise.Rn.Get(i) != rn {
+			t.Errorf("Interpolated data doesn't match at %v: got %v %v %v want %v %v %v\n", f, noise.NFmin.Get(i), noise.Gopt.Get(i), noise.Rn.Get(i), nfmin, gopt, rn)
+		}
+	}
+}
+
+func TestExtractNoise(t *testing.T) {
+	net := NewNetwork()
+	net.ReadTouchstone("./data/ntwk_noise.s2p")
+
+	gs := make([]*mat.CVector, net.Noise.Freq.NPts)
+	nf := make([]*mat.Vector, net.Noise.Freq.NPts)
+	for k := range gs {
+		gs[k] = cvf(0)
+		nf[k] = vf(0)
+		for _, val := range []complex128{0, 0.3, -0.3i, 0.5 + 0.2i, -0.4 + 0.4i, -0.6 - 0.1i} {
+			gs[k].Append(val)
+			nf[k].Append(NoiseFigure(net.Noise.NFmin.Get(k), net.Noise.Gopt.Get(k), net.Noise.Rn.Get(k), val))
+		}
+	}
+
+	noise, residual := ExtractNoise(net.Noise.Freq, gs, nf)
+	for k := 0; k < net.Noise.Freq.NPts; k++ {
+		if math.Abs(noise.NFmin.Get(k)-net.Noise.NFmin.Get(k)) > eps {
+			t.Errorf("NFmin doesn't match: got %v want %v\n", noise.NFmin.Get(k), net.Noise.NFmin.Get(k))
+		}
+		if cmplx.Abs(noise.Gopt.Get(k)-net.Noise.Gopt.Get(k)) > eps {
+			t.Errorf("Gopt doesn't match: got %v want %v\n", noise.Gopt.Get(k), net.Noise.Gopt.Get(k))
+		}
+		if math.Abs(noise.Rn.Get(k)-net.Noise.Rn.Get(k)) > eps {
+			t.Errorf("Rn doesn't match: got %v want %v\n", noise.Rn.Get(k), net.Noise.Rn.Get(k))
+		}
+		if residual.Get(k) > eps {
+			t.Errorf("Residual too large: got %v\n", residual.Get(k))
+		}
+	}
+}
+
+func TestYFactorNF(t *testing.T) {
+	// ENR 15 dB, Y 10 dB, hot/cold at 290 K: F = 10**1.5/9
+	if nf, res := YFactorNF(15, 10, t0), 10*math.Log10(math.Pow(10, 1.5)/9); math.Abs(nf-res) > eps {
+		t.Errorf("Noise figure doesn't match: got %v want %v\n", nf, res)
+	}
+}
@@ -0,0 +1,420 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+// passivityMargin is the distance inside the passivity boundary that
+// enforcement aims for
+const passivityMargin = 1e-3
+
+type PassivityReport struct {
+	Passive    bool
+	Bands      []FreqRange // violation bands (Hz) found before enforcement
+	Worst      float64     // largest violation found before enforcement
+	Iterations int
+}
+
+// CheckPassivity assesses the passivity of the model from the purely
+// imaginary eigenvalues of its Hamiltonian matrix, which mark the frequencies
+// where a singular value of S crosses 1 (or an eigenvalue of Re(Y) crosses
+// 0).  It returns the frequency bands (Hz) in which the model is not passive.
+// The model must not have a proportional term E when fitted in S.
+func (m *RationalModel) CheckPassivity() (bool, []FreqRange) {
+	bands := m.violations()
+	return len(bands) == 0, bands
+}
+
+// EnforcePassivity makes the model passive by iterative residue
+// perturbation.  D is first moved inside the passivity boundary if needed.
+// Each iteration locates the worst violation in every band found by the
+// Hamiltonian test and solves for the smallest change to the residues, in the
+// sense of the H2 norm of the change in the model, that moves the violating
+// singular values (or eigenvalues) just inside the boundary at those
+// frequencies under a first order approximation.  Reciprocal models stay
+// reciprocal.  RMS is left as the error of the original fit.
+func (m *RationalModel) EnforcePassivity(maxIter int) *PassivityReport {
+	rep := &PassivityReport{}
+	ws := m.scale()
+
+	if worst := m.violation(cmplx.Inf()); worst > -passivityMargin {
+		rep.Worst = worst
+		rep.Bands = append(rep.Bands, FreqRange{math.Inf(1), math.Inf(1)})
+		m.clipD()
+	}
+
+	for it := 0; it < maxIter; it++ {
+		bands := m.violations()
+		if len(bands) == 0 {
+			rep.Passive = true
+			break
+		}
+		rep.Iterations++
+
+		freqs := make([]float64, 0)
+		for _, band := range bands {
+			wmax, vmax := 0., math.Inf(-1)
+			for k := 0; k <= 20; k++ {
+				w := 2 * math.Pi * (band.Start + (band.Stop-band.Start)*float64(k)/20)
+				if v := m.violation(complex(0, w)); v > vmax {
+					wmax, vmax = w, v
+				}
+			}
+			freqs = append(freqs, wmax)
+			if it == 0 {
+				rep.Bands = append(rep.Bands, band)
+				rep.Worst = maxf64(rep.Worst, vmax)
+			}
+		}
+
+		m.perturbResidues(freqs, ws)
+	}
+
+	if !rep.Passive {
+		rep.Passive, _ = m.CheckPassivity()
+	}
+	return rep
+}
+
+// scale returns the largest pole magnitude, used to normalize frequency
+func (m *RationalModel) scale() float64 {
+	ws := 1.
+	for _, p := range m.Poles.Data {
+		ws = math.Max(ws, cmplx.Abs(p))
+	}
+	return ws
+}
+
+// violation returns how far the model is outside the passivity boundary at
+// s: the largest singular value less 1 for S, or minus the smallest
+// eigenvalue of the Hermitian part for Y.  Infinite s evaluates D.
+func (m *RationalModel) violation(s complex128) float64 {
+	var h *mat.CMatrix
+	if cmplx.IsInf(s) {
+		h = cmf(m.NPorts, m.NPorts, opts)
+		for k := range h.Data {
+			h.Data[k] = complex(m.D.Data[k], 0)
+		}
+	} else {
+		h = m.eval(s)
+	}
+
+	if m.Param == S {
+		sv, _, _ := csvd(h)
+		return sv[0] - 1
+	}
+	w, _ := cheig(hermitianPart(h))
+	return -w[0]
+}
+
+// hermitianPart returns (h + h**H)/2
+func hermitianPart(h *mat.CMatrix) *mat.CMatrix {
+	g := cmf(h.Rows, h.Cols, opts)
+	for i := 0; i < h.Rows; i++ {
+		for j := 0; j < h.Cols; j++ {
+			g.Set(i, j, (h.Get(i, j)+h.GetConj(j, i))/2)
+		}
+	}
+	return g
+}
+
+// clipD moves D inside the passivity boundary: the singular values of D are
+// limited to 1 - passivityMargin for S, and the eigenvalues of D + D**T to at
+// least passivityMargin for Y
+func (m *RationalModel) clipD() {
+	d := cmf(m.NPorts, m.NPorts, opts)
+	for i := 0; i < m.NPorts; i++ {
+		for j := 0; j < m.NPorts; j++ {
+			d.Set(i, j, complex(m.D.Get(i, j), 0))
+		}
+	}
+
+	if m.Param == S {
+		sv, u, vh := csvd(d)
+		for i := 0; i < m.NPorts; i++ {
+			for j := 0; j < m.NPorts; j++ {
+				val := 0.
+				for k := range sv {
+					val += real(u.Get(i, k)*vh.Get(k, j)) * math.Min(sv[k], 1-passivityMargin)
+				}
+				m.D.Set(i, j, val)
+			}
+		}
+		return
+	}
+
+	w, z := cheig(hermitianPart(d))
+	for i := 0; i < m.NPorts; i++ {
+		for j := 0; j < m.NPorts; j++ {
+			val := 0.
+			for k := range w {
+				val += real(z.Get(i, k)*cmplx.Conj(z.Get(j, k))) * math.Max(passivityMargin-w[k], 0)
+			}
+			m.D.Set(i, j, m.D.Get(i, j)+val)
+		}
+	}
+}
+
+// stateSpace returns a real state space realization A, B, C of the pole and
+// residue terms of the model with frequency normalized by ws
+func (m *RationalModel) stateSpace(ws float64) (a, b, c *mat.Matrix) {
+	np, nports := m.Poles.Size, m.NPorts
+	nx := np * nports
+	a, b, c = mf(nx, nx, opts), mf(nx, nports, opts), mf(nports, nx, opts)
+
+	for p := 0; p < np; p++ {
+		pole := m.Poles.Get(p) / complex(ws, 0)
+		off := p * nports
+		for i := 0; i < nports; i++ {
+			if imag(pole) == 0 {
+				a.Set(off+i, off+i, real(pole))
+				b.Set(off+i, i, 1)
+				for j := 0; j < nports; j++ {
+					c.Set(j, off+i, real(m.Residues[p].Get(j, i))/ws)
+				}
+			} else {
+				a.Set(off+i, off+i, real(pole))
+				a.Set(off+i, off+nports+i, imag(pole))
+				a.Set(off+nports+i, off+i, -imag(pole))
+				a.Set(off+nports+i, off+nports+i, real(pole))
+				b.Set(off+i, i, 2)
+				for j := 0; j < nports; j++ {
+					c.Set(j, off+i, real(m.Residues[p].Get(j, i))/ws)
+					c.Set(j, off+nports+i, imag(m.Residues[p].Get(j, i))/ws)
+				}
+			}
+		}
+		if imag(pole) != 0 {
+			p++
+		}
+	}
+
+	return a, b, c
+}
+
+// hamiltonian returns the Hamiltonian matrix of the model with frequency
+// normalized by ws
+func (m *RationalModel) hamiltonian(ws float64) *mat.Matrix {
+	a, b, c := m.stateSpace(ws)
+	d := m.D
+	bt, ct, dt := transpose(b), transpose(c), transpose(d)
+	nx := a.Rows
+
+	var m11, m12, m21, m22 *mat.Matrix
+	if m.Param == S {
+		r, q := mmul(dt, d), mmul(d, dt)
+		for i := 0; i < m.NPorts; i++ {
+			r.Set(i, i, r.Get(i, i)-1)
+			q.Set(i, i, q.Get(i, i)-1)
+		}
+		matInv(r)
+		matInv(q)
+
+		br := mmul(b, r)
+		m11 = mmul(mmul(br, dt), c)
+		m12 = mmul(br, bt)
+		m21 = mmul(mmul(ct, q), c)
+		m22 = mmul(mmul(mmul(ct, d), r), bt)
+		for k := range m11.Data {
+			m11.Data[k] = a.Data[k] - m11.Data[k]
+			m12.Data[k] = -m12.Data[k]
+		}
+	} else {
+		w := mf(m.NPorts, m.NPorts, opts)
+		for i := 0; i < m.NPorts; i++ {
+			for j := 0; j < m.NPorts; j++ {
+				w.Set(i, j, d.Get(i, j)+d.Get(j, i))
+			}
+		}
+		matInv(w)
+
+		bw := mmul(b, w)
+		m11 = mmul(bw, c)
+		m12 = mmul(bw, bt)
+		m21 = mmul(mmul(ct, w), c)
+		m22 = mmul(mmul(ct, w), bt)
+		for k := range m11.Data {
+			m11.Data[k] = a.Data[k] - m11.Data[k]
+			m21.Data[k] = -m21.Data[k]
+		}
+	}
+
+	h := mf(2*nx, 2*nx, opts)
+	for i := 0; i < nx; i++ {
+		for j := 0; j < nx; j++ {
+			h.Set(i, j, m11.Get(i, j))
+			h.Set(i, nx+j, m12.Get(i, j))
+			h.Set(nx+i, j, m21.Get(i, j))
+			h.Set(nx+i, nx+j, m22.Get(i, j)-a.Get(j, i))
+		}
+	}
+	return h
+}
+
+// violations returns the frequency bands (Hz) in which the model is not
+// passive
+func (m *RationalModel) violations() []FreqRange {
+	if m.Param != S && m.Param != Y {
+		panic("passivity only supported for S and Y models")
+	} else if m.Param == S {
+		for _, val := range m.E.Data {
+			if val != 0 {
+				panic("S model with a proportional term cannot be passive")
+			}
+		}
+	}
+
+	bands := make([]FreqRange, 0)
+	if m.violation(cmplx.Inf()) >= 0 {
+		return append(bands, FreqRange{math.Inf(1), math.Inf(1)})
+	}
+
+	ws := m.scale()
+	edges := []float64{0}
+	for _, val := range eig(m.hamiltonian(ws)) {
+		if imag(val) > 0 && math.Abs(real(val)) <= 1e-6*cmplx.Abs(val) {
+			edges = append(edges, imag(val)*ws)
+		}
+	}
+	sort.Float64s(edges)
+	edges = append(edges, 2*math.Max(edges[len(edges)-1], ws))
+
+	for k := 1; k < len(edges); k++ {
+		if edges[k]-edges[k-1] <= 1e-9*edges[k] {
+			continue
+		}
+		if m.violation(complex(0, (edges[k-1]+edges[k])/2)) > 0 {
+			start, stop := edges[k-1]/(2*math.Pi), edges[k]/(2*math.Pi)
+			if len(bands) > 0 && bands[len(bands)-1].Stop == start {
+				bands[len(bands)-1].Stop = stop
+			} else {
+				bands = append(bands, FreqRange{start, stop})
+			}
+		}
+	}
+	return bands
+}
+
+// perturbResidues applies the minimum H2 norm residue change that moves the
+// violations at frequencies w (rad/s) inside the passivity boundary to first
+// order
+func (m *RationalModel) perturbResidues(w []float64, ws float64) {
+	np, nports := m.Poles.Size, m.NPorts
+	poles := make([]complex128, np)
+	norm := make([]float64, np)
+	for p := range poles {
+		poles[p] = m.Poles.Get(p) / complex(ws, 0)
+		norm[p] = 1 / math.Sqrt(2*math.Abs(real(poles[p])))
+	}
+
+	sym := true
+	for p := range m.Residues {
+		for i := 0; i < nports; i++ {
+			for j := 0; j < i; j++ {
+				if cmplx.Abs(m.Residues[p].Get(i, j)-m.Residues[p].Get(j, i)) > 1e-12*cmplx.Abs(m.Residues[p].Get(i, j)) {
+					sym = false
+				}
+			}
+		}
+	}
+
+	// unknowns are the real basis coefficient changes for each element
+	elems := make([][2]int, 0)
+	for i := 0; i < nports; i++ {
+		for j := 0; j < nports; j++ {
+			if !sym || j >= i {
+				elems = append(elems, [2]int{i, j})
+			}
+		}
+	}
+	col := func(q, e int) int { return q*len(elems) + e }
+
+	rows := make([][]float64, 0)
+	rhs := make([]float64, 0)
+	for _, wk := range w {
+		h := m.eval(complex(0, wk))
+		phi := basis(complex(0, wk/ws), poles)
+
+		var vals []float64
+		var vecl, vecr [][]complex128
+		if m.Param == S {
+			sv, u, vh := csvd(h)
+			for k := range sv {
+				if sv[k] > 1-passivityMargin {
+					vals = append(vals, 1-passivityMargin-sv[k])
+					l, r := make([]complex128, nports), make([]complex128, nports)
+					for i := 0; i < nports; i++ {
+						l[i] = cmplx.Conj(u.Get(i, k))
+						r[i] = cmplx.Conj(vh.Get(k, i))
+					}
+					vecl, vecr = append(vecl, l), append(vecr, r)
+				}
+			}
+		} else {
+			ev, z := cheig(hermitianPart(h))
+			for k := range ev {
+				if ev[k] < passivityMargin {
+					vals = append(vals, passivityMargin-ev[k])
+					l, r := make([]complex128, nports), make([]complex128, nports)
+					for i := 0; i < nports; i++ {
+						l[i] = cmplx.Conj(z.Get(i, k))
+						r[i] = z.Get(i, k)
+					}
+					vecl, vecr = append(vecl, l), append(vecr, r)
+				}
+			}
+		}
+
+		for k := range vals {
+			row := make([]float64, np*len(elems))
+			for q := 0; q < np; q++ {
+				for e, ij := range elems {
+					val := real(phi[q] * vecl[k][ij[0]] * vecr[k][ij[1]])
+					if ij[0] != ij[1] && sym {
+						val += real(phi[q] * vecl[k][ij[1]] * vecr[k][ij[0]])
+					}
+					row[col(q, e)] = val / norm[q]
+				}
+			}
+			rows = append(rows, row)
+			rhs = append(rhs, vals[k])
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	a := mf(len(rows), np*len(elems), opts)
+	b := vf(len(rows))
+	for i := range rows {
+		for j := range rows[i] {
+			a.Set(i, j, rows[i][j])
+		}
+		b.Set(i, rhs[i])
+	}
+	x := lstsq(a, b)
+
+	for q := 0; q < np; q++ {
+		for e, ij := range elems {
+			dc := x.Get(col(q, e)) / norm[q] * ws
+			for _, pq := range [][2]int{ij, {ij[1], ij[0]}} {
+				if imag(poles[q]) == 0 {
+					m.Residues[q].Set(pq[0], pq[1], m.Residues[q].Get(pq[0], pq[1])+complex(dc, 0))
+				} else if imag(poles[q]) > 0 {
+					m.Residues[q].Set(pq[0], pq[1], m.Residues[q].Get(pq[0], pq[1])+complex(dc, 0))
+					m.Residues[q+1].Set(pq[0], pq[1], cmplx.Conj(m.Residues[q].Get(pq[0], pq[1])))
+				} else {
+					m.Residues[q-1].Set(pq[0], pq[1], m.Residues[q-1].Get(pq[0], pq[1])+complex(0, dc))
+					m.Residues[q].Set(pq[0], pq[1], cmplx.Conj(m.Residues[q-1].Get(pq[0], pq[1])))
+				}
+				if !sym || ij[0] == ij[1] {
+					break
+				}
+			}
+		}
+	}
+}
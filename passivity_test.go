@@ -0,0 +1,118 @@
+package gorf
+
+import (
+	"math"
+	"testing"
+)
+
+// maxViolation returns the largest passivity violation of m sampled on a dense
+// grid up to fmax
+func maxViolation(m *RationalModel, fmax float64) float64 {
+	worst := math.Inf(-1)
+	for k := 0; k <= 4000; k++ {
+		worst = math.Max(worst, m.violation(complex(0, 2*math.Pi*fmax*float64(k)/4000)))
+	}
+	return worst
+}
+
+func TestCheckPassivity(t *testing.T) {
+	m := testModel()
+	passive, bands := m.CheckPassivity()
+	if passive || len(bands) != 1 {
+		t.Fatalf("Violation bands don't match: got %v want 1 band\n", bands)
+	}
+	for _, f := range []float64{bands[0].Start, bands[0].Stop} {
+		if v := m.violation(complex(0, 2*math.Pi*f)); math.Abs(v) > 1e-6 {
+			t.Errorf("Band edge not on passivity boundary: got %v at %v\n", v, f)
+		}
+	}
+	if v := m.violation(complex(0, math.Pi*(bands[0].Start+bands[0].Stop))); v <= 0 {
+		t.Errorf("Band centre is passive: got %v\n", v)
+	}
+
+	for _, r := range m.Residues {
+		for k := range r.Data {
+			r.Data[k] *= 0.2
+		}
+	}
+	if passive, bands = m.CheckPassivity(); !passive || maxViolation(m, 20e9) > 0 {
+		t.Errorf("Passive model reported as violating: got %v\n", bands)
+	}
+}
+
+func TestEnforcePassivity(t *testing.T) {
+	m := testModel()
+	for _, r := range m.Residues {
+		for k := range r.Data {
+			r.Data[k] *= 4
+		}
+	}
+
+	rep := m.EnforcePassivity(20)
+	if !rep.Passive || len(rep.Bands) != 2 {
+		t.Errorf("Enforcement report doesn't match: got %+v\n", rep)
+	}
+	if passive, _ := m.CheckPassivity(); !passive {
+		t.Errorf("Model not passive after enforcement\n")
+	}
+	if v := maxViolation(m, 20e9); v > 0 {
+		t.Errorf("Model violates passivity after enforcement: got %v\n", v)
+	}
+
+	d := m.Residues[0].Get(0, 1)
+	rep = m.EnforcePassivity(20)
+	if rep.Iterations != 0 || m.Residues[0].Get(0, 1) != d {
+		t.Errorf("Passive model changed by enforcement: got %v iterations\n", rep.Iterations)
+	}
+}
+
+// yModel returns a 2-port Y model whose conductance goes negative around the
+// 3 GHz resonance
+func yModel() *RationalModel {
+	poles := []complex128{complex(-0.3e9, 2*math.Pi*3e9), complex(-0.3e9, -2*math.Pi*3e9), -2e9}
+	res := [][][]complex128{
+		{{-1.2e7, 0.3e7}, {0.3e7, -0.6e7}},
+		{{-1.2e7, 0.3e7}, {0.3e7, -0.6e7}},
+		{{2e7, -1e7}, {-1e7, 2e7}},
+	}
+
+	m := &RationalModel{NPorts: 2, Z0: cvf(2), Param: Y, Poles: cvf(0), D: mf(2, 2, opts), E: mf(2, 2, opts), RMS: mf(2, 2, opts)}
+	m.Z0.SetReAll(50)
+	for p := range poles {
+		m.Poles.Append(poles[p])
+		r := cmf(2, 2, opts)
+		for i := 0; i < 2; i++ {
+			for j := 0; j < 2; j++ {
+				r.Set(i, j, res[p][i][j])
+			}
+		}
+		m.Residues = append(m.Residues, r)
+	}
+	m.D.Set(0, 0, 0.02)
+	m.D.Set(1, 1, 0.02)
+	return m
+}
+
+func TestPassivityY(t *testing.T) {
+	m := yModel()
+	passive, bands := m.CheckPassivity()
+	if passive || len(bands) != 1 {
+		t.Fatalf("Violation bands don't match: got %v want 1 band\n", bands)
+	}
+	for _, f := range []float64{bands[0].Start, bands[0].Stop} {
+		if v := m.violation(complex(0, 2*math.Pi*f)); math.Abs(v) > 1e-9 {
+			t.Errorf("Band edge not on passivity boundary: got %v at %v\n", v, f)
+		}
+	}
+	if v := m.violation(complex(0, 2*math.Pi*3e9)); v <= 0 {
+		t.Errorf("Band centre is passive: got %v\n", v)
+	}
+
+	rep := m.EnforcePassivity(20)
+	if !rep.Passive || len(rep.Bands) != 1 {
+		t.Errorf("Enforcement report doesn't match: got %+v\n", rep)
+	}
+	if v := maxViolation(m, 20e9); v > 0 {
+		t.Errorf("Model violates passivity after enforcement: got %v\n", v)
+	}
+}
@@ -0,0 +1,93 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+type QFit struct {
+	F0       float64     // resonant frequency (Hz)
+	QL       float64     // loaded Q
+	Q0       float64     // unloaded Q
+	Coupling float64     // coupling coefficient of each port
+	Residual *mat.Vector // |Sij - fit| at each frequency
+	RMS      float64     // RMS of the residuals
+}
+
+// qFitIterations is the number of times the fit is repeated about the latest
+// estimate of the resonant frequency
+const qFitIterations = 5
+
+// FitResonance fits a single resonance in Sij, over the whole band of the
+// network, which should be cropped to the resonance.  Sij is fitted by least
+// squares with the linear fractional model
+//
+//	S(t) = (a1*t + a2)/(a3*t + 1), t = 2*(f - fr)/fr
+//
+// which is a Lorentzian circle on a constant background a1/a3, and f0 and QL
+// follow from the pole of the model.  The fit is repeated with fr at the
+// latest f0.
+//
+// For transmission, i != j, the ports are taken as equally coupled and the
+// circle diameter d, the resonant |S21| with the background removed, gives
+// Q0 = QL/(1 - d).  For reflection, i == j, the resonant S11 relative to the
+// background, r, gives the coupling (1 - r)/(1 + r), over coupled for r < 0,
+// and Q0 = QL*(1 + coupling).
+func (n *Network) FitResonance(i, j int) *QFit {
+	f := n.Freq.Freq.Data[:n.Freq.NPts]
+	s := n.sij(i, j)
+	if len(f) < 3 {
+		panic("resonance fit requires at least 3 frequencies")
+	}
+
+	q := &QFit{F0: f[len(f)/2]}
+	var a *mat.CVector
+	for it := 0; it < qFitIterations; it++ {
+		m := cmf(len(f), 3, opts)
+		b := cvf(len(f))
+		for k := range f {
+			t := complex(2*(f[k]-q.F0)/q.F0, 0)
+			m.Set(k, 0, t)
+			m.Set(k, 1, 1)
+			m.Set(k, 2, -t*s[k])
+			b.Set(k, s[k])
+		}
+		a = clstsq(m, b)
+
+		// the pole is at 1 + 2j*QL*(f - f0)/f0 = 0
+		p := 1 / a.Get(2)
+		fr := q.F0
+		q.F0 = fr * (1 - real(p)/2)
+		q.QL = -q.F0 / (fr * imag(p))
+		if it < qFitIterations-1 {
+			continue
+		}
+
+		q.Residual = vf(len(f))
+		sum := 0.
+		for k := range f {
+			t := complex(2*(f[k]-fr)/fr, 0)
+			e := cmplx.Abs(s[k] - (a.Get(0)*t+a.Get(1))/(a.Get(2)*t+1))
+			q.Residual.Set(k, e)
+			sum += e * e
+		}
+		q.RMS = math.Sqrt(sum / float64(len(f)))
+
+		bg := a.Get(0) / a.Get(2)
+		tq := complex(2*(q.F0-fr)/fr, 0)
+		res := (a.Get(1) - bg) / (a.Get(2)*tq + 1)
+		if i == j {
+			r := real((bg + res) / bg)
+			q.Coupling = (1 - r) / (1 + r)
+			q.Q0 = q.QL * (1 + q.Coupling)
+		} else {
+			d := cmplx.Abs(res)
+			q.Coupling = d / (2 * (1 - d))
+			q.Q0 = q.QL / (1 - d)
+		}
+	}
+
+	return q
+}
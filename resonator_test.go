@@ -0,0 +1,47 @@
+package gorf
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFitResonance(t *testing.T) {
+	freq := NewFrequencySweep(4.9, 5.1, 401, "ghz", Lin)
+	f0, ql := 5.0012e9, 1000.
+	lorentz := func(f float64) complex128 {
+		return 1 / complex(1, 2*ql*(f-f0)/f0)
+	}
+
+	tests := []struct {
+		name     string
+		i, j     int
+		s        func(f float64) complex128
+		q0, beta float64
+	}{
+		{"transmission", 1, 0, func(f float64) complex128 { return 0.01 + 0.02i + 0.3*lorentz(f) }, ql / 0.7, 0.3 / 1.4},
+		{"under coupled", 0, 0, func(f float64) complex128 { return 0.9 * (0.8 + 0.6i) * (1 - 2*0.5/1.5*lorentz(f)) }, ql * 1.5, 0.5},
+		{"over coupled", 0, 0, func(f float64) complex128 { return -0.95 * (1 - 2*2/3.*lorentz(f)) }, ql * 3, 2},
+	}
+	for _, test := range tests {
+		net := constantNetwork(freq, 50, [][]complex128{{0, 0}, {0, 0}})
+		for k, s := range net.Data {
+			s.Set(test.i, test.j, test.s(freq.Freq.Get(k)))
+		}
+
+		q := net.Crop(4.985e9, 5.015e9).FitResonance(test.i, test.j)
+		if q.Residual.Size != 61 {
+			t.Errorf("%v cropped points don't match: got %v want %v\n", test.name, q.Residual.Size, 61)
+		}
+		for _, v := range []struct {
+			name      string
+			got, want float64
+		}{{"f0", q.F0, f0}, {"QL", q.QL, ql}, {"Q0", q.Q0, test.q0}, {"coupling", q.Coupling, test.beta}} {
+			if math.Abs(v.got-v.want) > 1e-6*v.want {
+				t.Errorf("%v %v doesn't match: got %v want %v\n", test.name, v.name, v.got, v.want)
+			}
+		}
+		if q.RMS > 1e-9 {
+			t.Errorf("%v residual too large: got %v\n", test.name, q.RMS)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package gorf
+
+import (
+	"github.com/whipstein/golinalg/mat"
+)
+
+// SOLTCal is the 12-term error model of a two-port measurement: directivity
+// ED, source match ES, reflection tracking ER, isolation EX, load match EL and
+// transmission tracking ET, in the forward (F, port 1 driven) and reverse (R,
+// port 2 driven) directions
+type SOLTCal struct {
+	Freq                         *Frequency
+	Z0                           *mat.CVector
+	EDF, ESF, ERF, EXF, ELF, ETF *mat.CVector
+	EDR, ESR, ERR, EXR, ELR, ETR *mat.CVector
+}
+
+// NewSOLTCal solves the error model from short, open and load (or any three
+// or more reflection standards) measured on each port as 1-port networks, with
+// their ideal responses, and a thru measured as a 2-port.  A nil thruIdeal is
+// a flush thru, otherwise it holds the 2-port response of the thru, such as
+// from StandardDef.Network.  The isolation terms come from the raw 2-port
+// measurement with both ports terminated, or are zero if isolation is nil.
+func NewSOLTCal(port1, port1Ideal, port2, port2Ideal []*Network, thru, thruIdeal, isolation *Network) *SOLTCal {
+	p1 := NewOnePortCal(port1, port1Ideal)
+	p2 := NewOnePortCal(port2, port2Ideal)
+
+	freq := p1.Freq
+	for _, n := range []*Network{thru, thruIdeal, isolation} {
+		if n != nil && n.Freq.NPts != freq.NPts {
+			panic("standards must share the same frequencies")
+		}
+	}
+	if p2.Freq.NPts != freq.NPts {
+		panic("standards must share the same frequencies")
+	} else if thru.NPorts != 2 || (thruIdeal != nil && thruIdeal.NPorts != 2) {
+		panic("thru must be a 2-port network")
+	}
+
+	c := &SOLTCal{
+		Freq: freq.DeepCopy(),
+		Z0:   thru.Z0.DeepCopy(),
+		EDF:  p1.Ed, ESF: p1.Es, ERF: p1.Er,
+		EDR: p2.Ed, ESR: p2.Es, ERR: p2.Er,
+		EXF: cvf(freq.NPts), ELF: cvf(freq.NPts), ETF: cvf(freq.NPts),
+		EXR: cvf(freq.NPts), ELR: cvf(freq.NPts), ETR: cvf(freq.NPts),
+	}
+
+	raw := thru.S()
+	var iso, ideal []*mat.CMatrix
+	if isolation != nil {
+		iso = isolation.S()
+	}
+	if thruIdeal != nil {
+		ideal = thruIdeal.S()
+	}
+	for k := 0; k < freq.NPts; k++ {
+		t := cmf(2, 2, opts)
+		if ideal != nil {
+			t = ideal[k]
+		} else {
+			t.Set(0, 1, 1)
+			t.Set(1, 0, 1)
+		}
+		if iso != nil {
+			c.EXF.Set(k, iso[k].Get(1, 0))
+			c.EXR.Set(k, iso[k].Get(0, 1))
+		}
+
+		// reflection at each port of the thru gives the opposite load match
+		g1 := (raw[k].Get(0, 0) - c.EDF.Get(k)) / (c.ERF.Get(k) + c.ESF.Get(k)*(raw[k].Get(0, 0)-c.EDF.Get(k)))
+		q := g1 - t.Get(0, 0)
+		c.ELF.Set(k, q/(t.Get(0, 1)*t.Get(1, 0)+q*t.Get(1, 1)))
+		g2 := (raw[k].Get(1, 1) - c.EDR.Get(k)) / (c.ERR.Get(k) + c.ESR.Get(k)*(raw[k].Get(1, 1)-c.EDR.Get(k)))
+		q = g2 - t.Get(1, 1)
+		c.ELR.Set(k, q/(t.Get(0, 1)*t.Get(1, 0)+q*t.Get(0, 0)))
+
+		df, dr := c.denominators(k, t)
+		c.ETF.Set(k, (raw[k].Get(1, 0)-c.EXF.Get(k))*df/t.Get(1, 0))
+		c.ETR.Set(k, (raw[k].Get(0, 1)-c.EXR.Get(k))*dr/t.Get(0, 1))
+	}
+
+	return c
+}
+
+// denominators returns the forward and reverse signal flow denominators of
+// the error model around 2-port s at frequency index k
+func (c *SOLTCal) denominators(k int, s *mat.CMatrix) (complex128, complex128) {
+	det := s.Get(0, 0)*s.Get(1, 1) - s.Get(0, 1)*s.Get(1, 0)
+	df := 1 - c.ESF.Get(k)*s.Get(0, 0) - c.ELF.Get(k)*s.Get(1, 1) + c.ESF.Get(k)*c.ELF.Get(k)*det
+	dr := 1 - c.ESR.Get(k)*s.Get(1, 1) - c.ELR.Get(k)*s.Get(0, 0) + c.ESR.Get(k)*c.ELR.Get(k)*det
+	return df, dr
+}
+
+// Apply returns the corrected 2-port network, in S, from the raw measurement
+func (c *SOLTCal) Apply(raw *Network) *Network {
+	out := c.check(raw)
+	for k, m := range out.Data {
+		a := (m.Get(0, 0) - c.EDF.Get(k)) / c.ERF.Get(k)
+		b := (m.Get(1, 0) - c.EXF.Get(k)) / c.ETF.Get(k)
+		cc := (m.Get(0, 1) - c.EXR.Get(k)) / c.ETR.Get(k)
+		d := (m.Get(1, 1) - c.EDR.Get(k)) / c.ERR.Get(k)
+		esf, esr, elf, elr := c.ESF.Get(k), c.ESR.Get(k), c.ELF.Get(k), c.ELR.Get(k)
+
+		den := (1+a*esf)*(1+d*esr) - b*cc*elf*elr
+		m.Set(0, 0, (a*(1+d*esr)-elf*b*cc)/den)
+		m.Set(1, 0, b*(1+d*(esr-elf))/den)
+		m.Set(0, 1, cc*(1+a*(esf-elr))/den)
+		m.Set(1, 1, (d*(1+a*esf)-elr*b*cc)/den)
+	}
+	return out
+}
+
+// Embed returns the raw measurement, in S, that the error model predicts for
+// the 2-port network dut
+func (c *SOLTCal) Embed(dut *Network) *Network {
+	out := c.check(dut)
+	for k, s := range out.Data {
+		det := s.Get(0, 0)*s.Get(1, 1) - s.Get(0, 1)*s.Get(1, 0)
+		df, dr := c.denominators(k, s)
+		s11 := c.EDF.Get(k) + c.ERF.Get(k)*(s.Get(0, 0)-c.ELF.Get(k)*det)/df
+		s21 := c.EXF.Get(k) + c.ETF.Get(k)*s.Get(1, 0)/df
+		s12 := c.EXR.Get(k) + c.ETR.Get(k)*s.Get(0, 1)/dr
+		s22 := c.EDR.Get(k) + c.ERR.Get(k)*(s.Get(1, 1)-c.ELR.Get(k)*det)/dr
+		s.Set(0, 0, s11)
+		s.Set(1, 0, s21)
+		s.Set(0, 1, s12)
+		s.Set(1, 1, s22)
+	}
+	return out
+}
+
+// check returns a copy of 2-port n in S after checking it against the
+// calibration
+func (c *SOLTCal) check(n *Network) *Network {
+	if n.NPorts != 2 {
+		panic("two-port calibration applies to 2-port networks")
+	} else if n.Freq.NPts != c.Freq.NPts {
+		panic("network and calibration frequencies differ")
+	}
+
+	out := n.DeepCopy()
+	out.Data = n.S()
+	out.Param = S
+	return out
+}
@@ -0,0 +1,115 @@
+package gorf
+
+import (
+	"math/cmplx"
+	"testing"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+// soltErrors returns a frequency dependent set of 12-term error terms on freq
+func soltErrors(freq *Frequency) *SOLTCal {
+	term := func(mag, tau float64) *mat.CVector {
+		v := cvf(freq.NPts)
+		for k := 0; k < freq.NPts; k++ {
+			v.Set(k, cmplx.Rect(mag, -freq.W.Get(k)*tau))
+		}
+		return v
+	}
+
+	c := &SOLTCal{Freq: freq.DeepCopy(), Z0: cvf(2)}
+	c.Z0.SetReAll(50)
+	c.EDF, c.ESF, c.ERF, c.EXF, c.ELF, c.ETF = term(0.05, -1e-11), term(0.1, 2e-11), term(0.8, 1e-10), term(1e-3, 0), term(0.08, 3e-11), term(0.7, 2e-10)
+	c.EDR, c.ESR, c.ERR, c.EXR, c.ELR, c.ETR = term(0.04, 2e-11), term(0.12, -1e-11), term(0.75, 1.2e-10), term(2e-3, 0), term(0.09, 1e-11), term(0.72, 2e-10)
+	return c
+}
+
+// port returns the 1-port raw measurement of reflection standard std on port
+// i of the error model
+func (c *SOLTCal) port(i int, std *Network) *Network {
+	one := &OnePortCal{Freq: c.Freq, Z0: std.Z0, Ed: c.EDF, Es: c.ESF, Er: c.ERF}
+	if i == 1 {
+		one.Ed, one.Es, one.Er = c.EDR, c.ESR, c.ERR
+	}
+	return one.Embed(std)
+}
+
+func TestStandardDef(t *testing.T) {
+	freq := NewFrequencySweep(1, 10, 10, "ghz", Lin)
+
+	short := StandardDef{Type: ShortStandard}.Network(freq, 50)
+	open := StandardDef{Type: OpenStandard, OffsetZ0: 50, OffsetDelay: 30e-12}.Network(freq, 50)
+	for k := 0; k < freq.NPts; k++ {
+		if s := short.Data[k].Get(0, 0); cmplx.Abs(s+1) > eps {
+			t.Errorf("Flush short doesn't match: got %v want -1\n", s)
+		}
+		res := cmplx.Exp(complex(0, -2*freq.W.Get(k)*30e-12))
+		if s := open.Data[k].Get(0, 0); cmplx.Abs(s-res) > eps {
+			t.Errorf("Offset open doesn't match: got %v want %v\n", s, res)
+		}
+	}
+
+	flush := StandardDef{Type: OpenStandard}.Network(freq, 50)
+	for k := 0; k < freq.NPts; k++ {
+		if s := flush.Data[k].Get(0, 0); cmplx.IsNaN(s) || cmplx.Abs(s-1) > eps {
+			t.Errorf("Flush open doesn't match: got %v want 1\n", s)
+		}
+	}
+	dc := NewFrequencySweep(0, 10, 11, "ghz", Lin)
+	copen := StandardDef{Type: OpenStandard, OffsetZ0: 50, OffsetDelay: 30e-12, C: [4]float64{50e-15}}.Network(dc, 50)
+	if s := copen.Data[0].Get(0, 0); cmplx.IsNaN(s) || cmplx.Abs(s-1) > eps {
+		t.Errorf("Capacitive open at DC doesn't match: got %v want 1\n", s)
+	}
+
+	lossy := StandardDef{Type: ShortStandard, OffsetZ0: 50, OffsetDelay: 30e-12, OffsetLoss: 2e9}.Network(freq, 50)
+	if ok, _ := lossy.IsLossless(1e-3); ok {
+		t.Errorf("Lossy offset short reported lossless\n")
+	}
+}
+
+func TestSOLTCal(t *testing.T) {
+	dut := NewNetwork()
+	dut.ReadTouchstone("./data/line.s2p")
+	freq := dut.Freq
+	truth := soltErrors(freq)
+
+	defs := []StandardDef{
+		{Type: ShortStandard, OffsetZ0: 50, OffsetDelay: 10e-12, L: [4]float64{2e-12, 1e-22}},
+		{Type: OpenStandard, OffsetZ0: 50, OffsetDelay: 12e-12, C: [4]float64{5e-15, 1e-26, 1e-36}},
+		{Type: LoadStandard, OffsetZ0: 50, Load: 51},
+	}
+	ideal := make([]*Network, 0)
+	raw1, raw2 := make([]*Network, 0), make([]*Network, 0)
+	for _, d := range defs {
+		std := d.Network(freq, 50)
+		ideal = append(ideal, std)
+		raw1 = append(raw1, truth.port(0, std))
+		raw2 = append(raw2, truth.port(1, std))
+	}
+	thruIdeal := StandardDef{Type: ThruStandard, OffsetZ0: 50, OffsetDelay: 20e-12}.Network(freq, 50)
+	thru := truth.Embed(thruIdeal)
+	isolation := truth.Embed(constantNetwork(freq, 50, [][]complex128{{0, 0}, {0, 0}}))
+
+	cal := NewSOLTCal(raw1, ideal, raw2, ideal, thru, thruIdeal, isolation)
+	got := cal.Apply(truth.Embed(dut))
+	for k := range got.Data {
+		for i := 0; i < 2; i++ {
+			for j := 0; j < 2; j++ {
+				if cmplx.Abs(got.Data[k].Get(i, j)-dut.Data[k].Get(i, j)) > eps {
+					t.Errorf("Corrected S%v%v doesn't match: got %v want %v\n", i+1, j+1, got.Data[k].Get(i, j), dut.Data[k].Get(i, j))
+				}
+			}
+		}
+		if cmplx.Abs(cal.ETF.Get(k)-truth.ETF.Get(k)) > eps || cmplx.Abs(cal.ELR.Get(k)-truth.ELR.Get(k)) > eps {
+			t.Errorf("Error terms don't match: got %v %v want %v %v\n", cal.ETF.Get(k), cal.ELR.Get(k), truth.ETF.Get(k), truth.ELR.Get(k))
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != "standards must share the same frequencies" {
+			t.Errorf("Short ideal thru not rejected: got %v\n", r)
+		}
+	}()
+	short := StandardDef{Type: ThruStandard, OffsetZ0: 50, OffsetDelay: 20e-12}.Network(NewFrequencySweep(1, 2, freq.NPts-1, "ghz", Lin), 50)
+	NewSOLTCal(raw1, ideal, raw2, ideal, thru, short, isolation)
+}
@@ -0,0 +1,183 @@
+package gorf
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// WriteSpiceSubckt writes the network as a SPICE subcircuit with terminals
+// named from PortNames (p1, p2, ... where unset) followed by the reference
+// node ref.  Each port sees its real Z0 behind a wave-controlled source.
+//
+// With a nil model the S-parameters are written as PSpice style frequency
+// tables (E ... FREQ), one per element, as understood by PSpice and Xyce.
+// Otherwise model, a rational fit of the network in S or Y, is synthesized as
+// an equivalent circuit of R, C, E, G, F and V elements only, which runs in
+// any SPICE including ngspice.  Enforce passivity on the model first for a
+// stable transient simulation.
+func (n *Network) WriteSpiceSubckt(filename string, model *RationalModel) {
+	if model != nil && model.NPorts != n.NPorts {
+		panic("model and network port counts differ")
+	}
+
+	ports := make([]string, n.NPorts)
+	for i := range ports {
+		ports[i] = fmt.Sprintf("p%v", i+1)
+		if i < len(n.PortNames) && strings.TrimSpace(n.PortNames[i]) != "" {
+			ports[i] = strings.Join(strings.Fields(n.PortNames[i]), "_")
+		}
+	}
+	name := "network"
+	if strings.TrimSpace(n.Name) != "" {
+		name = strings.Join(strings.Fields(n.Name), "_")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "* %v\n", name)
+	for _, line := range strings.Split(strings.TrimSpace(n.Comments), "\n") {
+		if line = strings.TrimSpace(strings.TrimLeft(line, "!")); line != "" {
+			fmt.Fprintf(&b, "* %v\n", line)
+		}
+	}
+	fmt.Fprintf(&b, ".subckt %v %v ref\n", name, strings.Join(ports, " "))
+
+	if model == nil {
+		n.spiceTable(&b, ports)
+	} else {
+		model.spiceCircuit(&b, ports)
+	}
+
+	fmt.Fprintf(&b, ".ends %v\n", name)
+
+	w := NewWriter(filename)
+	w.Write("%v", b.String())
+}
+
+// spiceWaves writes the port terminations: port i connects through Z0 to node
+// n_i, and node a_i carries 2*sqrt(Z0)*a, the scaled incident wave
+func spiceWaves(b *strings.Builder, ports []string, z0 []float64) {
+	for i, p := range ports {
+		fmt.Fprintf(b, "R0_%v %v n_%v %.12g\n", i+1, p, i+1, z0[i])
+		fmt.Fprintf(b, "Ea_%v a_%v m_%v %v ref 1\n", i+1, i+1, i+1, p)
+		fmt.Fprintf(b, "Em_%v m_%v ref %v n_%v 1\n", i+1, i+1, p, i+1)
+	}
+}
+
+// spiceTable writes each Sij as a frequency table source in series at port i
+func (n *Network) spiceTable(b *strings.Builder, ports []string) {
+	z0 := make([]float64, n.NPorts)
+	for i := range z0 {
+		z0[i] = n.Z0.GetRe(i)
+	}
+	spiceWaves(b, ports, z0)
+
+	data := n.S()
+	for i := 0; i < n.NPorts; i++ {
+		for j := 0; j < n.NPorts; j++ {
+			top, bottom := fmt.Sprintf("n_%v_%v", i+1, j), fmt.Sprintf("n_%v_%v", i+1, j+1)
+			if j == 0 {
+				top = fmt.Sprintf("n_%v", i+1)
+			}
+			if j == n.NPorts-1 {
+				bottom = "ref"
+			}
+			fmt.Fprintf(b, "E%v_%v %v %v FREQ {V(a_%v,ref)} R_I =", i+1, j+1, top, bottom, j+1)
+			scale := math.Sqrt(z0[i] / z0[j])
+			for k := 0; k < n.Freq.NPts; k++ {
+				val := data[k].Get(i, j) * complex(scale, 0)
+				fmt.Fprintf(b, "\n+ (%.12g, %.12g, %.12g)", n.Freq.Freq.Get(k), real(val), imag(val))
+			}
+			fmt.Fprintf(b, "\n")
+		}
+	}
+}
+
+// spiceCircuit writes the state space realization of the model.  State nodes
+// have a 1/ws capacitor to ref, so that the pole terms are realized with
+// resistors and transconductances of order one.  For Y the port currents are
+// drawn directly from the ports; for S the outgoing waves are summed on a 1
+// ohm node s_i that drives the source behind Z0 at port i.
+func (m *RationalModel) spiceCircuit(b *strings.Builder, ports []string) {
+	ws := m.scale()
+	a, bm, c := m.stateSpace(ws)
+	cx := 1 / ws
+
+	// input nodes carry k_j times the input of port j
+	in := make([]string, m.NPorts)
+	k := make([]float64, m.NPorts)
+	out := make([]string, m.NPorts)
+	switch m.Param {
+	case S:
+		z0 := make([]float64, m.NPorts)
+		for i := range z0 {
+			z0[i] = m.Z0.GetRe(i)
+			in[i] = fmt.Sprintf("a_%v", i+1)
+			k[i] = 2 * math.Sqrt(z0[i])
+			out[i] = fmt.Sprintf("s_%v", i+1)
+		}
+		spiceWaves(b, ports, z0)
+		for i := range out {
+			fmt.Fprintf(b, "Rs_%v %v ref 1\n", i+1, out[i])
+			fmt.Fprintf(b, "Eb_%v n_%v ref %v ref %.12g\n", i+1, i+1, out[i], -k[i])
+		}
+	case Y:
+		for i := range in {
+			in[i], k[i], out[i] = ports[i], 1, ports[i]
+		}
+	default:
+		panic("SPICE synthesis only supports S and Y models")
+	}
+
+	// states: dx/dt = ws*(A x + B u) with A and B normalized by ws
+	for x := 0; x < a.Rows; x++ {
+		node := fmt.Sprintf("x_%v", x+1)
+		fmt.Fprintf(b, "Cx_%v %v ref %.12g\n", x+1, node, cx)
+		if a.Get(x, x) != 0 {
+			fmt.Fprintf(b, "Rx_%v %v ref %.12g\n", x+1, node, -1/a.Get(x, x))
+		}
+		for y := 0; y < a.Cols; y++ {
+			if y != x && a.Get(x, y) != 0 {
+				fmt.Fprintf(b, "Gx_%v_%v ref %v x_%v ref %.12g\n", x+1, y+1, node, y+1, a.Get(x, y))
+			}
+		}
+		for j := 0; j < m.NPorts; j++ {
+			if bm.Get(x, j) != 0 {
+				fmt.Fprintf(b, "Gu_%v_%v ref %v %v ref %.12g\n", x+1, j+1, node, in[j], bm.Get(x, j)/k[j])
+			}
+		}
+	}
+
+	// outputs: y = C x + D u + E du/dt
+	for i := 0; i < m.NPorts; i++ {
+		for x := 0; x < c.Cols; x++ {
+			if c.Get(i, x) != 0 {
+				fmt.Fprintf(b, "Gy_%v_%v %v ref x_%v ref %.12g\n", i+1, x+1, out[i], x+1, c.Get(i, x))
+			}
+		}
+		for j := 0; j < m.NPorts; j++ {
+			if m.D.Get(i, j) != 0 {
+				fmt.Fprintf(b, "Gd_%v_%v %v ref %v ref %.12g\n", i+1, j+1, out[i], in[j], m.D.Get(i, j)/k[j])
+			}
+		}
+	}
+
+	for j := 0; j < m.NPorts; j++ {
+		used := false
+		for i := 0; i < m.NPorts; i++ {
+			used = used || m.E.Get(i, j) != 0
+		}
+		if !used {
+			continue
+		}
+		// the current through a unit capacitor driven by the input is du/dt
+		fmt.Fprintf(b, "Ee_%v e_%v ref %v ref 1\n", j+1, j+1, in[j])
+		fmt.Fprintf(b, "Ve_%v e_%v f_%v 0\n", j+1, j+1, j+1)
+		fmt.Fprintf(b, "Ce_%v f_%v ref 1\n", j+1, j+1)
+		for i := 0; i < m.NPorts; i++ {
+			if m.E.Get(i, j) != 0 {
+				fmt.Fprintf(b, "Fe_%v_%v %v ref Ve_%v %.12g\n", i+1, j+1, out[i], j+1, m.E.Get(i, j)/k[j])
+			}
+		}
+	}
+}
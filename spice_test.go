@@ -0,0 +1,199 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+// spiceY solves the subcircuit in filename by modified nodal analysis at
+// frequency f (Hz) and returns its admittance matrix.  Only the elements
+// written by WriteSpiceSubckt are understood, and FREQ tables are looked up
+// at f exactly.
+func spiceY(filename string, f float64) (*mat.CMatrix, []string) {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		panic(err)
+	}
+	lines := make([]string, 0)
+	for _, line := range strings.Split(string(raw), "\n") {
+		if strings.HasPrefix(line, "+") {
+			lines[len(lines)-1] += line[1:]
+		} else if line = strings.TrimSpace(line); line != "" && line[0] != '*' {
+			lines = append(lines, line)
+		}
+	}
+
+	var ports []string
+	nodes := map[string]int{"ref": -1}
+	node := func(name string) int {
+		if _, ok := nodes[name]; !ok {
+			nodes[name] = len(nodes) - 1
+		}
+		return nodes[name]
+	}
+	branches := map[string]int{}
+	elems := make([][]string, 0)
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		switch {
+		case fields[0] == ".subckt":
+			ports = fields[2 : len(fields)-1]
+			for _, p := range ports {
+				node(p)
+			}
+		case fields[0][0] == '.':
+		default:
+			node(fields[1])
+			node(fields[2])
+			if (fields[0][0] == 'E' && fields[3] != "FREQ") || fields[0][0] == 'G' {
+				node(fields[3])
+				node(fields[4])
+			}
+			if fields[0][0] == 'E' || fields[0][0] == 'V' {
+				branches[fields[0]] = len(branches)
+			}
+			elems = append(elems, fields)
+		}
+	}
+	for _, p := range ports {
+		branches["port "+p] = len(branches)
+	}
+
+	nn := len(nodes) - 1
+	size := nn + len(branches)
+	w := 2 * math.Pi * f
+	a := cmf(size, size, opts)
+	add := func(i, j int, val complex128) {
+		if i >= 0 && j >= 0 {
+			a.Set(i, j, a.Get(i, j)+val)
+		}
+	}
+	val := func(s string) float64 {
+		x, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			panic(err)
+		}
+		return x
+	}
+	for _, e := range elems {
+		p, m := nodes[e[1]], nodes[e[2]]
+		switch e[0][0] {
+		case 'R', 'C':
+			y := complex(1/val(e[3]), 0)
+			if e[0][0] == 'C' {
+				y = complex(0, w*val(e[3]))
+			}
+			add(p, p, y)
+			add(m, m, y)
+			add(p, m, -y)
+			add(m, p, -y)
+		case 'G':
+			cp, cm, g := nodes[e[3]], nodes[e[4]], complex(val(e[5]), 0)
+			add(p, cp, g)
+			add(p, cm, -g)
+			add(m, cp, -g)
+			add(m, cm, g)
+		case 'F':
+			k, g := nn+branches[e[3]], complex(val(e[4]), 0)
+			add(p, k, g)
+			add(m, k, -g)
+		case 'E', 'V':
+			k := nn + branches[e[0]]
+			add(p, k, 1)
+			add(m, k, -1)
+			add(k, p, 1)
+			add(k, m, -1)
+			if e[0][0] == 'V' {
+				break
+			}
+			var g complex128
+			var cp, cm int
+			if e[3] == "FREQ" {
+				ctrl := strings.Split(strings.Trim(e[4], "{V()}"), ",")
+				cp, cm = nodes[ctrl[0]], nodes[ctrl[1]]
+				table := strings.Fields(strings.NewReplacer("(", " ", ")", " ", ",", " ").Replace(strings.Join(e[7:], " ")))
+				for t := 0; t < len(table); t += 3 {
+					if math.Abs(val(table[t])-f) <= 1e-9*f {
+						g = complex(val(table[t+1]), val(table[t+2]))
+					}
+				}
+			} else {
+				cp, cm, g = nodes[e[3]], nodes[e[4]], complex(val(e[5]), 0)
+			}
+			add(k, cp, -g)
+			add(k, cm, g)
+		}
+	}
+	for _, p := range ports {
+		k := nn + branches["port "+p]
+		add(nodes[p], k, 1)
+		add(k, nodes[p], 1)
+	}
+	cmatInv(a)
+
+	// column j of Y is minus the port source currents with port j at 1 V
+	y := cmf(len(ports), len(ports), opts)
+	for i, pi := range ports {
+		for j, pj := range ports {
+			y.Set(i, j, -a.Get(nn+branches["port "+pi], nn+branches["port "+pj]))
+		}
+	}
+	return y, ports
+}
+
+func TestWriteSpiceSubckt(t *testing.T) {
+	net := delayNetwork(0.2e-9)
+	net.Name = "delay line"
+	net.PortNames[0] = "in"
+	filename := filepath.Join(t.TempDir(), "delay.cir")
+
+	net.WriteSpiceSubckt(filename, nil)
+	for _, k := range []int{0, 37, 99} {
+		y, ports := spiceY(filename, net.Freq.Freq.Get(k))
+		if len(ports) != 2 || ports[0] != "in" || ports[1] != "p2" {
+			t.Fatalf("Port names don't match: got %v want [in p2]\n", ports)
+		}
+		want := net.Y()[k]
+		for i := 0; i < 2; i++ {
+			for j := 0; j < 2; j++ {
+				if cmplx.Abs(y.Get(i, j)-want.Get(i, j)) > 1e-9*cmplx.Abs(want.Get(i, j)) {
+					t.Errorf("Table Y%v%v doesn't match: got %v want %v\n", i+1, j+1, y.Get(i, j), want.Get(i, j))
+				}
+			}
+		}
+	}
+
+	for _, param := range []RFParam{S, Y} {
+		m := testModel()
+		m.Param = param
+		m.E.Set(0, 1, 1e-11)
+		m.E.Set(1, 0, 1e-11)
+		model := m.Evaluate(net.Freq)
+		model.Z0 = net.Z0.DeepCopy()
+
+		net.WriteSpiceSubckt(filename, m)
+		for _, k := range []int{0, 37, 99} {
+			y, _ := spiceY(filename, net.Freq.Freq.Get(k))
+			var want *mat.CMatrix
+			if param == S {
+				want = model.Y()[k]
+			} else {
+				want = model.Data[k]
+			}
+			for i := 0; i < 2; i++ {
+				for j := 0; j < 2; j++ {
+					if cmplx.Abs(y.Get(i, j)-want.Get(i, j)) > 1e-9*cmplx.Abs(want.Get(i, j)) {
+						t.Errorf("Synthesized Y%v%v doesn't match: got %v want %v\n", i+1, j+1, y.Get(i, j), want.Get(i, j))
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+type StandardType int
+
+const (
+	OpenStandard StandardType = iota
+	ShortStandard
+	LoadStandard
+	ThruStandard
+)
+
+// StandardDef defines a calibration standard as in VNA cal kits: a
+// termination behind an offset line of impedance OffsetZ0, one-way delay
+// OffsetDelay (s) and loss OffsetLoss (ohm/s at 1 GHz).  An open terminates in
+// capacitance C0 + C1*f + C2*f**2 + C3*f**3 (F, f in Hz), a short in
+// inductance L0 + L1*f + L2*f**2 + L3*f**3 (H) and a load in impedance Load.
+// A thru is the offset line alone.
+type StandardDef struct {
+	Type        StandardType
+	OffsetZ0    float64
+	OffsetDelay float64
+	OffsetLoss  float64
+	C           [4]float64
+	L           [4]float64
+	Load        complex128
+}
+
+// Network returns the standard on freq in S with real reference impedance
+// z0, a 1-port for reflection standards and a 2-port for a thru.  A zero
+// OffsetZ0 is taken as z0.
+func (d StandardDef) Network(freq *Frequency, z0 float64) *Network {
+	line := offsetLine{z0: d.OffsetZ0, delay: d.OffsetDelay, loss: d.OffsetLoss}
+	if line.z0 == 0 {
+		line.z0 = z0
+	}
+	if d.Type == ThruStandard {
+		return Line(line, freq, 1, z0)
+	}
+
+	net := NewNetwork()
+	net.SetPorts(1)
+	net.Z0.SetReAll(z0)
+	net.Param = S
+	net.Freq = freq.DeepCopy()
+
+	for k := 0; k < freq.NPts; k++ {
+		w, f := freq.W.Get(k), freq.Freq.Get(k)
+		var zl complex128
+		switch d.Type {
+		case OpenStandard:
+			zl = capacitance(w, d.C[0]+d.C[1]*f+d.C[2]*f*f+d.C[3]*f*f*f)
+		case ShortStandard:
+			zl = complex(0, w*(d.L[0]+d.L[1]*f+d.L[2]*f*f+d.L[3]*f*f*f))
+		case LoadStandard:
+			zl = d.Load
+		default:
+			panic("standard type not recognized")
+		}
+
+		zc := line.Zc(w)
+		gl := 1 + 0i
+		if !cmplx.IsInf(zl) {
+			gl = (zl - zc) / (zl + zc)
+		}
+		gl *= cmplx.Exp(-2 * line.Gamma(w))
+
+		// renormalize the reflection from zc to z0, which stays finite for
+		// the open, gl = 1, that an input impedance would not
+		r := (zc - complex(z0, 0)) / (zc + complex(z0, 0))
+		s := cmf(1, 1, opts)
+		s.Set(0, 0, (gl+r)/(1+r*gl))
+		net.Data = append(net.Data, s)
+	}
+	return net
+}
+
+// offsetLine is the offset line of a standard, as a medium whose propagation
+// constant is that of the whole line
+type offsetLine struct {
+	z0, delay, loss float64
+}
+
+func (o offsetLine) Zc(w float64) complex128 {
+	if w == 0 || o.loss == 0 {
+		return complex(o.z0, 0)
+	}
+	x := o.loss / (2 * w) * math.Sqrt(w/(2*math.Pi*1e9))
+	return complex(o.z0+x, -x)
+}
+
+func (o offsetLine) Gamma(w float64) complex128 {
+	x := o.loss * o.delay / (2 * o.z0) * math.Sqrt(w/(2*math.Pi*1e9))
+	return complex(x, w*o.delay+x)
+}
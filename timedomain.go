@@ -0,0 +1,334 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+type Window int
+
+const (
+	NoWindow Window = iota
+	Hamming
+	Kaiser
+)
+
+// kaiserBeta is the Kaiser window shape used for time domain transforms,
+// equivalent to the "normal" window setting of most VNAs
+const kaiserBeta = 6.
+
+// coefficients returns the n point symmetric window
+func (w Window) coefficients(n int) []float64 {
+	c := make([]float64, n)
+	for k := range c {
+		if n == 1 {
+			c[k] = 1
+			continue
+		}
+		x := float64(k) / float64(n-1)
+		switch w {
+		case NoWindow:
+			c[k] = 1
+		case Hamming:
+			c[k] = 0.54 - 0.46*math.Cos(2*math.Pi*x)
+		case Kaiser:
+			r := 2*x - 1
+			c[k] = besselI0(kaiserBeta*math.Sqrt(1-r*r)) / besselI0(kaiserBeta)
+		default:
+			panic("window not recognized")
+		}
+	}
+	return c
+}
+
+type TDMode int
+
+const (
+	LowPass TDMode = iota
+	BandPass
+)
+
+// ImpulseResponse returns the time axis (s) and impulse response of Sij.  In
+// LowPass mode the data is extrapolated to DC and resampled onto a harmonic
+// grid, giving a real response with dt = 1/(2*fstop) nominally.  In BandPass
+// mode the measured, uniformly spaced, points are used as they are and the
+// magnitude of the complex response is returned.
+func (n *Network) ImpulseResponse(i, j int, mode TDMode, win Window) (*mat.Vector, *mat.Vector) {
+	f, s := n.spectrum(i, j, mode)
+	t, h := toTime(f, s, mode, win)
+
+	resp := vf(len(h))
+	for k, val := range h {
+		if mode == LowPass {
+			resp.Set(k, real(val))
+		} else {
+			resp.Set(k, cmplx.Abs(val))
+		}
+	}
+
+	return vdf(t), resp
+}
+
+// StepResponse returns the time axis (s) and low pass step response of Sij,
+// which settles to the measured or extrapolated DC value of Sij
+func (n *Network) StepResponse(i, j int, win Window) (*mat.Vector, *mat.Vector) {
+	t, h := n.ImpulseResponse(i, j, LowPass, win)
+
+	sum := 0.
+	for k := range h.Data {
+		sum += h.Get(k)
+		h.Set(k, sum)
+	}
+
+	return t, h
+}
+
+// spectrum returns Sij on the frequency grid (Hz) used for the transform
+func (n *Network) spectrum(i, j int, mode TDMode) ([]float64, []complex128) {
+	f := n.Freq.Freq.Data[:n.Freq.NPts]
+	s := n.sij(i, j)
+
+	if mode == BandPass {
+		if !isUniform(f) {
+			panic("band pass transform requires uniformly spaced frequencies")
+		}
+		return append([]float64{}, f...), s
+	} else if mode != LowPass {
+		panic("time domain mode not recognized")
+	}
+
+	mag, ang, dcmag, dcang := dcExtrapolate(f, s)
+
+	return harmonicResample(f, mag, ang, dcmag, dcang)
+}
+
+// harmonicResample resamples the magnitude and unwrapped phase of s(f), with
+// the DC point from dcExtrapolate, onto the grid k*fstop/npts, k = 0..npts,
+// where the spacing is the mean spacing of f.  A measured DC point is used
+// in place of the extrapolated one.
+func harmonicResample(f, mag, ang []float64, dcmag, dcang float64) ([]float64, []complex128) {
+	x := f
+	if f[0] != 0 {
+		x = append([]float64{0}, f...)
+		mag = append([]float64{dcmag}, mag...)
+		ang = append([]float64{dcang}, ang...)
+	}
+
+	df := f[0]
+	if len(f) > 1 {
+		df = (f[len(f)-1] - f[0]) / float64(len(f)-1)
+	}
+	npts := int(math.Round(f[len(f)-1] / df))
+	fh := make([]float64, npts+1)
+	sh := make([]complex128, npts+1)
+	for k := range fh {
+		fh[k] = f[len(f)-1] * float64(k) / float64(npts)
+		sh[k] = cmplx.Rect(interp1(x, mag, fh[k]), interp1(x, ang, fh[k]))
+	}
+	sh[0] = complex(dcmag*math.Cos(dcang), 0)
+
+	return fh, sh
+}
+
+// dcFitPoints is the number of low frequency points used to extrapolate to DC
+const dcFitPoints = 5
+
+// dcExtrapolate estimates the DC value of s(f) from its low frequency trend.
+// The unwrapped phase is shifted by a multiple of 2*pi so that its linear
+// trend passes through 0 or +-pi at DC, which makes the DC value real, and the
+// magnitude is extrapolated linearly and limited to [0, 1].  The phase of the
+// DC point is returned on the same branch as the shifted data.  A measured DC
+// point, f[0] = 0, gives the DC value from its real part instead.
+func dcExtrapolate(f []float64, s []complex128) (mag, ang []float64, dcmag, dcang float64) {
+	mag = make([]float64, len(s))
+	ang = make([]float64, len(s))
+	for k, val := range s {
+		mag[k], ang[k] = cmplx.Polar(val)
+	}
+	ang = unwrap(ang)
+
+	m := minint(len(f), dcFitPoints)
+	deg := minint(1, m-1)
+	c := polyfit(f[:m], ang[:m], deg)[0]
+	offset := 2 * math.Pi * math.Round(c/(2*math.Pi))
+	for k := range ang {
+		ang[k] -= offset
+	}
+	c -= offset
+
+	dcmag = math.Max(0, math.Min(1, polyval(polyfit(f[:m], mag[:m], deg), 0)))
+	neg := c > math.Pi/2 || c < -math.Pi/2
+	if f[0] == 0 {
+		dcmag, neg = math.Abs(real(s[0])), real(s[0]) < 0
+	}
+	if neg && c < 0 {
+		dcang = -math.Pi
+	} else if neg {
+		dcang = math.Pi
+	}
+
+	return mag, ang, dcmag, dcang
+}
+
+// toTime transforms the spectrum s on grid f to the time domain.  The result
+// is centred so that t = 0 falls at index len(t)/2.
+func toTime(f []float64, s []complex128, mode TDMode, win Window) ([]float64, []complex128) {
+	var x []complex128
+
+	if mode == LowPass {
+		k := len(s) - 1
+		w := win.coefficients(2*k + 1)[k:]
+		x = make([]complex128, 2*k+1)
+		x[0] = complex(real(s[0])*w[0], 0)
+		for i := 1; i <= k; i++ {
+			x[i] = s[i] * complex(w[i], 0)
+			x[2*k+1-i] = cmplx.Conj(x[i])
+		}
+	} else {
+		w := win.coefficients(len(s))
+		x = make([]complex128, len(s))
+		for i := range s {
+			x[i] = s[i] * complex(w[i], 0)
+		}
+	}
+
+	df := f[1] - f[0]
+	h := fft(x, true)
+	npts := len(h)
+	dt := 1 / (float64(npts) * df)
+
+	t := make([]float64, npts)
+	out := make([]complex128, npts)
+	for i := range out {
+		out[i] = h[(i+npts-npts/2)%npts]
+		t[i] = float64(i-npts/2) * dt
+	}
+
+	return t, out
+}
+
+// isUniform reports whether the points of f are equally spaced
+func isUniform(f []float64) bool {
+	if len(f) < 2 {
+		return false
+	}
+
+	df := (f[len(f)-1] - f[0]) / float64(len(f)-1)
+	for k := 1; k < len(f); k++ {
+		if math.Abs(f[k]-f[k-1]-df) > 1e-6*math.Abs(df) {
+			return false
+		}
+	}
+	return true
+}
+
+type Gate int
+
+const (
+	GateBandPass Gate = iota
+	GateNotch
+)
+
+// TimeGate returns a copy of the network, in S, with Sij gated in the time
+// domain.  The band pass transform of Sij is multiplied by a gate of the given
+// window shape spanning span seconds around center, or by its complement for
+// GateNotch, and transformed back.  For GateBandPass the result is divided by
+// the gated response of an ideal impulse at center, which removes the roll-off
+// the gate causes at the band edges, except where that response vanishes.
+func (n *Network) TimeGate(i, j int, center, span float64, gate Gate, win Window) *Network {
+	f, s := n.spectrum(i, j, BandPass)
+	t, h := toTime(f, s, BandPass, NoWindow)
+	g := gateCoefficients(t, center, span, gate, win)
+
+	for k := range h {
+		h[k] *= complex(g[k], 0)
+	}
+	sg := fromTime(h)
+
+	if gate == GateBandPass {
+		ref := make([]complex128, len(f))
+		for k := range ref {
+			ref[k] = cmplx.Exp(complex(0, -2*math.Pi*f[k]*center))
+		}
+		_, href := toTime(f, ref, BandPass, NoWindow)
+		for k := range href {
+			href[k] *= complex(g[k], 0)
+		}
+		comp := fromTime(href)
+		for k := range sg {
+			if cmplx.Abs(comp[k]) > 1e3*epsf64 {
+				sg[k] *= ref[k] / comp[k]
+			}
+		}
+	}
+
+	out := n.DeepCopy()
+	out.Data = n.S()
+	out.Param = S
+	for k := range sg {
+		out.Data[k].Set(i, j, sg[k])
+	}
+
+	return out
+}
+
+// gateCoefficients returns the time gate evaluated on t
+func gateCoefficients(t []float64, center, span float64, gate Gate, win Window) []float64 {
+	g := make([]float64, len(t))
+	idx := make([]int, 0)
+	for k, val := range t {
+		if math.Abs(val-center) <= span/2 {
+			idx = append(idx, k)
+		}
+	}
+
+	w := win.coefficients(len(idx))
+	for k, val := range idx {
+		g[val] = w[k]
+	}
+
+	switch gate {
+	case GateBandPass:
+	case GateNotch:
+		for k := range g {
+			g[k] = 1 - g[k]
+		}
+	default:
+		panic("gate type not recognized")
+	}
+
+	return g
+}
+
+// fromTime is the inverse of toTime for a band pass transform
+func fromTime(h []complex128) []complex128 {
+	npts := len(h)
+	x := make([]complex128, npts)
+	for i := range h {
+		x[(i+npts-npts/2)%npts] = h[i]
+	}
+	return fft(x, false)
+}
+
+// ImpedanceProfile returns the TDR impedance seen looking into port against
+// time (s) and one-way distance (m) along a line with the given velocity
+// factor, from the Kaiser windowed low pass step response of Sii.  Only t >= 0
+// is returned.
+func (n *Network) ImpedanceProfile(port int, velocityFactor float64) (t, d, z *mat.Vector) {
+	tstep, rho := n.StepResponse(port, port, Kaiser)
+	z0 := n.Z0.GetRe(port)
+
+	t, d, z = vf(0), vf(0), vf(0)
+	for k, val := range tstep.Data {
+		if val < 0 {
+			continue
+		}
+		t.Append(val)
+		d.Append(c0 * velocityFactor * val / 2)
+		z.Append(z0 * (1 + rho.Get(k)) / (1 - rho.Get(k)))
+	}
+
+	return t, d, z
+}
@@ -0,0 +1,157 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// delayNetwork returns a matched 2-port delay of tau seconds sampled from
+// 0.1 to 10 GHz
+func delayNetwork(tau float64) *Network {
+	net := NewNetwork()
+	net.SetPorts(2)
+	net.Z0.SetReAll(50)
+	net.Freq.Setup("ghz")
+	for k := 1; k <= 100; k++ {
+		net.Freq.Append(float64(k) * 0.1)
+		s := cmf(2, 2, opts)
+		s21 := cmplx.Exp(complex(0, -net.Freq.W.Get(k-1)*tau))
+		s.Set(0, 1, s21)
+		s.Set(1, 0, s21)
+		net.Data = append(net.Data, s)
+	}
+	return net
+}
+
+func TestFFT(t *testing.T) {
+	for _, n := range []int{1, 7, 16, 201} {
+		x := make([]complex128, n)
+		for j := range x {
+			x[j] = complex(math.Sin(float64(j)), math.Cos(3*float64(j)))
+		}
+		got := fft(x, false)
+		for k := range got {
+			var res complex128
+			for j := range x {
+				res += x[j] * cmplx.Exp(complex(0, -2*math.Pi*float64(j*k%n)/float64(n)))
+			}
+			if cmplx.Abs(got[k]-res) > 1e-9*float64(n) {
+				t.Errorf("Transform of length %v doesn't match at %v: got %v want %v\n", n, k, got[k], res)
+			}
+		}
+		back := fft(got, true)
+		for j := range back {
+			if cmplx.Abs(back[j]-x[j]) > 1e-9 {
+				t.Errorf("Inverse of length %v doesn't match at %v: got %v want %v\n", n, j, back[j], x[j])
+			}
+		}
+	}
+}
+
+func TestImpulseResponse(t *testing.T) {
+	dt := 1 / (201 * 0.1e9)
+	net := delayNetwork(10 * dt)
+
+	tm, h := net.ImpulseResponse(1, 0, LowPass, NoWindow)
+	if tm.Size != 201 || h.Size != 201 {
+		t.Fatalf("Number of points doesn't match: got %v want %v\n", tm.Size, 201)
+	}
+	if math.Abs(tm.Get(1)-tm.Get(0)-dt) > eps*dt {
+		t.Errorf("Time step doesn't match: got %v want %v\n", tm.Get(1)-tm.Get(0), dt)
+	}
+	for k := range h.Data {
+		res := 0.
+		if k == 110 {
+			res = 1
+		}
+		if math.Abs(h.Get(k)-res) > eps {
+			t.Errorf("Impulse response doesn't match at %v: got %v want %v\n", tm.Get(k), h.Get(k), res)
+		}
+	}
+
+	net = delayNetwork(5 / (100 * 0.1e9))
+	_, hb := net.ImpulseResponse(1, 0, BandPass, NoWindow)
+	if math.Abs(hb.Get(55)-1) > eps {
+		t.Errorf("Band pass impulse response doesn't match: got %v want %v\n", hb.Get(55), 1)
+	}
+}
+
+func TestStepResponse(t *testing.T) {
+	net := delayNetwork(0)
+	for k := range net.Data {
+		net.Data[k].Set(0, 0, -1)
+	}
+
+	for _, win := range []Window{NoWindow, Hamming, Kaiser} {
+		_, st := net.StepResponse(0, 0, win)
+		if math.Abs(st.Get(st.Size-1)+1) > eps {
+			t.Errorf("Step response doesn't settle: got %v want %v\n", st.Get(st.Size-1), -1)
+		}
+		if math.Abs(st.Get(0)) > 1e-6 {
+			t.Errorf("Step response doesn't start at 0: got %v\n", st.Get(0))
+		}
+	}
+
+	// a measured DC point is kept rather than extrapolated
+	series := SeriesImpedance(NewFrequencySweep(0, 10, 101, "ghz", Lin), Capacitor(1e-12, 0, 0, 0), 50)
+	_, st := series.StepResponse(1, 0, Kaiser)
+	if math.Abs(st.Get(st.Size-1)) > eps {
+		t.Errorf("Series capacitor step response doesn't settle: got %v want %v\n", st.Get(st.Size-1), 0)
+	}
+}
+
+func TestTimeGate(t *testing.T) {
+	tau1, tau2 := 0.5e-9, 3e-9
+	net := delayNetwork(tau1)
+	for k := range net.Data {
+		w := net.Freq.W.Get(k)
+		net.Data[k].Set(1, 0, cmplx.Exp(complex(0, -w*tau1))+0.3*cmplx.Exp(complex(0, -w*tau2)))
+	}
+
+	gated := net.TimeGate(1, 0, tau1, 1e-9, GateBandPass, Kaiser)
+	notched := net.TimeGate(1, 0, tau1, 1e-9, GateNotch, Kaiser)
+	for k := 10; k < 90; k++ {
+		w := net.Freq.W.Get(k)
+		if res := cmplx.Exp(complex(0, -w*tau1)); cmplx.Abs(gated.Data[k].Get(1, 0)-res) > 1e-6 {
+			t.Errorf("Gated response doesn't match at %v: got %v want %v\n", net.Freq.Freq.Get(k), gated.Data[k].Get(1, 0), res)
+		}
+		if res := 0.3 * cmplx.Exp(complex(0, -w*tau2)); cmplx.Abs(notched.Data[k].Get(1, 0)-res) > 1e-6 {
+			t.Errorf("Notched response doesn't match at %v: got %v want %v\n", net.Freq.Freq.Get(k), notched.Data[k].Get(1, 0), res)
+		}
+	}
+	if gated.Data[0].Get(0, 1) != net.Data[0].Get(0, 1) {
+		t.Errorf("Ungated parameter changed: got %v want %v\n", gated.Data[0].Get(0, 1), net.Data[0].Get(0, 1))
+	}
+
+	// a gate narrower than the time step passes nothing
+	empty := net.TimeGate(1, 0, tau1+1e-13, 1e-14, GateBandPass, Kaiser)
+	for k := range empty.Data {
+		if res := empty.Data[k].Get(1, 0); cmplx.IsNaN(res) || cmplx.Abs(res) > eps {
+			t.Errorf("Empty gate response doesn't match at %v: got %v want %v\n", net.Freq.Freq.Get(k), res, 0)
+		}
+	}
+}
+
+func TestImpedanceProfile(t *testing.T) {
+	tau := 1e-9
+	net := delayNetwork(0)
+	net.SetPorts(1)
+	net.Z0.SetReAll(50)
+	for k := range net.Data {
+		net.Data[k] = cmf(1, 1, opts)
+		net.Data[k].Set(0, 0, -cmplx.Exp(complex(0, -2*net.Freq.W.Get(k)*tau)))
+	}
+
+	tm, d, z := net.ImpedanceProfile(0, 0.7)
+	for k := range tm.Data {
+		if math.Abs(d.Get(k)-c0*0.7*tm.Get(k)/2) > eps {
+			t.Errorf("Distance doesn't match: got %v want %v\n", d.Get(k), c0*0.7*tm.Get(k)/2)
+		}
+		if tm.Get(k) < 3*tau/2 && math.Abs(z.Get(k)-50) > 0.1 {
+			t.Errorf("Impedance doesn't match at %v: got %v want %v\n", tm.Get(k), z.Get(k), 50)
+		} else if tm.Get(k) > 5*tau/2 && tm.Get(k) < 4*tau && math.Abs(z.Get(k)) > 0.1 {
+			t.Errorf("Impedance doesn't match at %v: got %v want %v\n", tm.Get(k), z.Get(k), 0)
+		}
+	}
+}
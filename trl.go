@@ -0,0 +1,277 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+// TRLCal is the 8-term error model of a two-port measurement, M = A*T*B in
+// T-parameters, solved by thru-reflect-line calibration.  The reference planes
+// are at the centre of the zero length thru and the reference impedance is
+// the characteristic impedance of the lines.
+type TRLCal struct {
+	Freq    *Frequency
+	Z0      *mat.CVector
+	A, B    []*mat.CMatrix // error boxes as T-matrices at each frequency
+	Gamma   *mat.CVector   // propagation constant of the lines (1/m)
+	Reflect *mat.CVector   // reflection of the reflect standard
+}
+
+// NewTRLCal solves the error model from the raw 2-port measurements of a
+// zero length thru, a reflect pair (the same unknown reflection on both
+// ports) and one or more matched lines of lengths (m) longer than the thru.
+// reflectEstimate, such as -1 for a short, resolves the sign of the reflect.
+//
+// Each line gives the error box eigenvectors from A*L*inv(A) =
+// Mline*inv(Mthru), where the root whose eigenvector lies closer to the first
+// axis is taken for exp(-gamma*l).  Several lines are combined as in NIST
+// multiline TRL, with the thru as the common line of every pair: gamma and
+// each eigenvector are the Gauss-Markov estimates over the pairs, weighted by
+// the inverse of their covariance.  The covariance follows from independent,
+// equal errors in the line measurements, which the common thru correlates
+// between the pairs and which reach the eigenvectors divided by
+// exp(-gamma*l) - exp(gamma*l), so lines near a multiple of a half wavelength
+// drop out.
+//
+// The phase of gamma*l is unwrapped over frequency from its principal value
+// at the first frequency, and the other lines take their branch from the
+// shortest, so the shortest line must be less than pi of phase long at the
+// first frequency.
+func NewTRLCal(thru, reflect *Network, lines []*Network, lengths []float64, reflectEstimate complex128) *TRLCal {
+	if len(lines) == 0 || len(lines) != len(lengths) {
+		panic("TRL calibration needs a length for each of one or more lines")
+	}
+	freq := thru.Freq
+	for _, n := range append([]*Network{thru, reflect}, lines...) {
+		if n.NPorts != 2 {
+			panic("TRL standards must be 2-port networks")
+		} else if n.Freq.NPts != freq.NPts {
+			panic("standards must share the same frequencies")
+		}
+	}
+
+	c := &TRLCal{
+		Freq:    freq.DeepCopy(),
+		Z0:      thru.Z0.DeepCopy(),
+		Gamma:   cvf(freq.NPts),
+		Reflect: cvf(freq.NPts),
+	}
+
+	mt := thru.T()
+	mr := reflect.S()
+	ml := make([][]*mat.CMatrix, len(lines))
+	for i := range lines {
+		ml[i] = lines[i].T()
+	}
+
+	mtInv := make([]*mat.CMatrix, freq.NPts)
+	for k := range mtInv {
+		mtInv[k] = cmatInv(mt[k].DeepCopy())
+	}
+
+	// per line eigenvectors, [1, q1] and [p2, 1], eigenvalue exp(-gamma*l)
+	// and gamma*l with phase unwrapped in frequency
+	q1 := make([][]complex128, len(lines))
+	p2 := make([][]complex128, len(lines))
+	el := make([][]complex128, len(lines))
+	gl := make([][]complex128, len(lines))
+	for i := range lines {
+		q1[i] = make([]complex128, freq.NPts)
+		p2[i] = make([]complex128, freq.NPts)
+		el[i] = make([]complex128, freq.NPts)
+		gl[i] = make([]complex128, freq.NPts)
+		ang := make([]float64, freq.NPts)
+		for k := 0; k < freq.NPts; k++ {
+			q1[i][k], p2[i][k], el[i][k] = trlEigen(cmmul(ml[i][k], mtInv[k]))
+			gl[i][k] = -cmplx.Log(el[i][k])
+			ang[k] = imag(gl[i][k])
+		}
+		ang = unwrap(ang)
+		for k := range ang {
+			gl[i][k] = complex(real(gl[i][k]), ang[k])
+		}
+	}
+
+	// the shortest line fixes the phase branch of the others
+	order := make([]int, len(lines))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return lengths[order[a]] < lengths[order[b]] })
+	ref := order[0]
+	for _, i := range order[1:] {
+		for k := 0; k < freq.NPts; k++ {
+			est := imag(gl[ref][k]) / lengths[ref] * lengths[i]
+			n := math.Round((est - imag(gl[i][k])) / (2 * math.Pi))
+			gl[i][k] += complex(0, 2*math.Pi*n)
+		}
+	}
+
+	for k := 0; k < freq.NPts; k++ {
+		// covariances of the per line estimates, to within a common factor,
+		// from errors e in the lines and et in the thru: gamma*l sees
+		// e/exp(-gamma*l) - et, and the eigenvectors see e - et/exp(-gamma*l)
+		// and e - et*exp(-gamma*l) divided by the eigenvalue difference
+		nl := len(lines)
+		vg, vq, vp := cmf(nl, nl, opts), cmf(nl, nl, opts), cmf(nl, nl, opts)
+		x, y, xq, yq, yp := make([]complex128, nl), make([]complex128, nl), make([]complex128, nl), make([]complex128, nl), make([]complex128, nl)
+		for i := 0; i < nl; i++ {
+			ei := el[i][k]
+			di := ei - 1/ei
+			if cmplx.Abs(di) < 1e-12 {
+				di = 1e-12
+			}
+			for j := 0; j < nl; j++ {
+				ej := el[j][k]
+				dj := ej - 1/ej
+				if cmplx.Abs(dj) < 1e-12 {
+					dj = 1e-12
+				}
+				var delta complex128
+				if i == j {
+					delta = 1
+				}
+				vg.Set(i, j, delta/complex(math.Pow(cmplx.Abs(ei), 2), 0)+1)
+				vq.Set(i, j, (delta+1/(ei*cmplx.Conj(ej)))/(di*cmplx.Conj(dj)))
+				vp.Set(i, j, (delta+ei*cmplx.Conj(ej))/(di*cmplx.Conj(dj)))
+			}
+			x[i], y[i] = complex(lengths[i], 0), gl[i][k]
+			xq[i], yq[i], yp[i] = 1, q1[i][k], p2[i][k]
+		}
+		c.Gamma.Set(k, gaussMarkov(x, y, vg))
+		q := gaussMarkov(xq, yq, vq)
+		p := gaussMarkov(xq, yp, vp)
+
+		// A = P*diag(k, 1) with P = [[1, p], [q, 1]]; the reflect fixes k
+		w1, w2 := mr[k].Get(0, 0), mr[k].Get(1, 1)
+		pm := cmf(2, 2, opts)
+		pm.Set(0, 0, 1)
+		pm.Set(0, 1, p)
+		pm.Set(1, 0, q)
+		pm.Set(1, 1, 1)
+		g := cmmul(cmatInv(pm.DeepCopy()), mt[k])
+		kg := (p - w1) / (w1*q - 1)
+		gk := (g.Get(1, 0) + w2*g.Get(1, 1)) / (g.Get(0, 0) + w2*g.Get(0, 1))
+		refl := cmplx.Sqrt(kg * gk)
+		if cmplx.Abs(refl-reflectEstimate) > cmplx.Abs(refl+reflectEstimate) {
+			refl = -refl
+		}
+		c.Reflect.Set(k, refl)
+		scale := kg / refl
+
+		// split M_thru = A*B into reciprocal error boxes, det(A) = 1
+		a := pm.DeepCopy()
+		a.Set(0, 0, a.Get(0, 0)*scale)
+		a.Set(1, 0, a.Get(1, 0)*scale)
+		norm := 1 / cmplx.Sqrt(a.Get(0, 0)*a.Get(1, 1)-a.Get(0, 1)*a.Get(1, 0))
+		for idx := range a.Data {
+			a.Data[idx] *= norm
+		}
+		c.A = append(c.A, a)
+		c.B = append(c.B, cmmul(cmatInv(a.DeepCopy()), mt[k]))
+	}
+
+	return c
+}
+
+// gaussMarkov returns the Gauss-Markov, or best linear unbiased, estimate of
+// the scalar a in y = a*x + e, where the errors e have covariance v
+func gaussMarkov(x, y []complex128, v *mat.CMatrix) complex128 {
+	vi := cmatInv(v.DeepCopy())
+	var num, den complex128
+	for i := range x {
+		for j := range x {
+			num += cmplx.Conj(x[i]) * vi.Get(i, j) * y[j]
+			den += cmplx.Conj(x[i]) * vi.Get(i, j) * x[j]
+		}
+	}
+	return num / den
+}
+
+// trlEigen returns the eigenvectors of the 2x2 matrix m as [1, q1] and
+// [p2, 1], the first being the one closer to the first axis, and the
+// eigenvalue belonging to the first.  Each eigenvector is taken from the row
+// of m - l*I with the larger coefficients, so neither is lost when m is
+// diagonal.
+func trlEigen(m *mat.CMatrix) (complex128, complex128, complex128) {
+	tr := m.Get(0, 0) + m.Get(1, 1)
+	det := m.Get(0, 0)*m.Get(1, 1) - m.Get(0, 1)*m.Get(1, 0)
+	d := cmplx.Sqrt(tr*tr - 4*det)
+	l := []complex128{(tr + d) / 2, (tr - d) / 2}
+
+	x, y := make([]complex128, 2), make([]complex128, 2)
+	for i, val := range l {
+		a, b := m.Get(0, 0)-val, m.Get(0, 1)
+		if c, e := m.Get(1, 0), m.Get(1, 1)-val; cmplx.Abs(c)+cmplx.Abs(e) > cmplx.Abs(a)+cmplx.Abs(b) {
+			a, b = c, e
+		}
+		x[i], y[i] = b, -a
+		if a == 0 && b == 0 {
+			x[i], y[i] = complex(float64(1-i), 0), complex(float64(i), 0)
+		}
+	}
+
+	if cmplx.Abs(x[0]*y[1]) < cmplx.Abs(x[1]*y[0]) {
+		return y[1] / x[1], x[0] / y[0], l[1]
+	}
+	return y[0] / x[0], x[1] / y[1], l[0]
+}
+
+// Apply returns the corrected 2-port network, in S, from the raw measurement
+func (c *TRLCal) Apply(raw *Network) *Network {
+	out := c.check(raw)
+	t := raw.T()
+	for k := range out.Data {
+		d := cmmul(cmmul(cmatInv(c.A[k].DeepCopy()), t[k]), cmatInv(c.B[k].DeepCopy()))
+		out.Data[k] = TtoS(d, out.Z0)
+	}
+	return out
+}
+
+// Embed returns the raw measurement, in S, that the error model predicts for
+// the 2-port network dut
+func (c *TRLCal) Embed(dut *Network) *Network {
+	out := c.check(dut)
+	t := dut.T()
+	for k := range out.Data {
+		out.Data[k] = TtoS(cmmul(cmmul(c.A[k], t[k]), c.B[k]), out.Z0)
+	}
+	return out
+}
+
+// ErrorBoxes returns the error boxes as 2-port networks in S, port 1 of A
+// facing the VNA port 1 and port 2 of B facing VNA port 2.  The error boxes
+// are taken as reciprocal, which leaves the sign of their transmission
+// undetermined.
+func (c *TRLCal) ErrorBoxes() (a, b *Network) {
+	a, b = NewNetwork(), NewNetwork()
+	for i, boxes := range [][]*mat.CMatrix{c.A, c.B} {
+		net := []*Network{a, b}[i]
+		net.SetPorts(2)
+		net.Z0 = c.Z0.DeepCopy()
+		net.Param = S
+		net.Freq = c.Freq.DeepCopy()
+		for _, t := range boxes {
+			net.Data = append(net.Data, TtoS(t.DeepCopy(), net.Z0))
+		}
+	}
+	return a, b
+}
+
+// check returns a copy of 2-port n in S after checking it against the
+// calibration
+func (c *TRLCal) check(n *Network) *Network {
+	if n.NPorts != 2 {
+		panic("two-port calibration applies to 2-port networks")
+	} else if n.Freq.NPts != c.Freq.NPts {
+		panic("network and calibration frequencies differ")
+	}
+
+	out := n.DeepCopy()
+	out.Data = n.S()
+	out.Param = S
+	return out
+}
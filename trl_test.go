@@ -0,0 +1,128 @@
+package gorf
+
+import (
+	"math/cmplx"
+	"testing"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+// errorBox returns a reciprocal, frequency dependent 2-port on freq
+func errorBox(freq *Frequency, s11, s21, s22 complex128, tau float64) *Network {
+	net := constantNetwork(freq, 50, [][]complex128{{s11, s21}, {s21, s22}})
+	for k, s := range net.Data {
+		d := cmplx.Exp(complex(0, -freq.W.Get(k)*tau))
+		s.Set(0, 1, s.Get(0, 1)*d)
+		s.Set(1, 0, s.Get(1, 0)*d)
+		s.Set(1, 1, s.Get(1, 1)*d*d)
+	}
+	return net
+}
+
+// cascade returns the raw measurement of dut between error boxes a and b
+func cascade(a, dut, b *Network) *Network {
+	out := dut.DeepCopy()
+	out.Param = S
+	ta, t, tb := a.T(), dut.T(), b.T()
+	out.Data = make([]*mat.CMatrix, len(t))
+	for k := range t {
+		out.Data[k] = TtoS(cmmul(cmmul(ta[k], t[k]), tb[k]), out.Z0)
+	}
+	return out
+}
+
+func TestTRLCal(t *testing.T) {
+	dut := NewNetwork()
+	dut.ReadTouchstone("./data/line.s2p")
+	freq := dut.Freq
+
+	a := errorBox(freq, 0.1+0.05i, 0.9, -0.08+0.1i, 20e-12)
+	b := errorBox(freq, 0.05-0.1i, 0.85i, 0.12, 15e-12)
+	tl := TEMLine{Z0: 50, EpsEff: 2.5, AlphaC: 5}
+	short := StandardDef{Type: ShortStandard, OffsetDelay: 0.5e-12}.Network(freq, 50)
+	reflect := constantNetwork(freq, 50, [][]complex128{{0, 0}, {0, 0}})
+	for k, r := range reflect.Data {
+		g, sa, sb := short.Data[k].Get(0, 0), a.Data[k], b.Data[k]
+		r.Set(0, 0, sa.Get(0, 0)+sa.Get(0, 1)*sa.Get(1, 0)*g/(1-sa.Get(1, 1)*g))
+		r.Set(1, 1, sb.Get(1, 1)+sb.Get(0, 1)*sb.Get(1, 0)*g/(1-sb.Get(0, 0)*g))
+	}
+
+	thru := cascade(a, Line(tl, freq, 0, 50), b)
+	lengths := []float64{1.5e-3, 0.4e-3, 0.9e-3}
+	lines := make([]*Network, len(lengths))
+	for i, l := range lengths {
+		lines[i] = cascade(a, Line(tl, freq, l, 50), b)
+	}
+	raw := cascade(a, dut, b)
+
+	for _, n := range []int{1, 3} {
+		cal := NewTRLCal(thru, reflect, lines[3-n:], lengths[3-n:], -1)
+		got := cal.Apply(raw)
+		for k := range got.Data {
+			for i := 0; i < 2; i++ {
+				for j := 0; j < 2; j++ {
+					if cmplx.Abs(got.Data[k].Get(i, j)-dut.Data[k].Get(i, j)) > eps {
+						t.Errorf("Corrected S%v%v doesn't match: got %v want %v\n", i+1, j+1, got.Data[k].Get(i, j), dut.Data[k].Get(i, j))
+					}
+				}
+			}
+			if res := tl.Gamma(freq.W.Get(k)); cmplx.Abs(cal.Gamma.Get(k)-res) > eps*cmplx.Abs(res) {
+				t.Errorf("Propagation constant doesn't match: got %v want %v\n", cal.Gamma.Get(k), res)
+			}
+			if res := short.Data[k].Get(0, 0); cmplx.Abs(cal.Reflect.Get(k)-res) > eps {
+				t.Errorf("Reflect doesn't match: got %v want %v\n", cal.Reflect.Get(k), res)
+			}
+		}
+
+		ea, eb := cal.ErrorBoxes()
+		for k := range ea.Data {
+			if s := ea.Data[k].Get(0, 0); cmplx.Abs(s-a.Data[k].Get(0, 0)) > eps {
+				t.Errorf("Error box directivity doesn't match: got %v want %v\n", s, a.Data[k].Get(0, 0))
+			}
+			if s := eb.Data[k].Get(1, 1); cmplx.Abs(s-b.Data[k].Get(1, 1)) > eps {
+				t.Errorf("Error box match doesn't match: got %v want %v\n", s, b.Data[k].Get(1, 1))
+			}
+		}
+	}
+
+	// with ideal error boxes the line eigenvectors lie on the axes
+	ideal := constantNetwork(freq, 50, [][]complex128{{0, 0}, {0, 0}})
+	for k, r := range ideal.Data {
+		r.Set(0, 0, short.Data[k].Get(0, 0))
+		r.Set(1, 1, short.Data[k].Get(0, 0))
+	}
+	cal := NewTRLCal(Line(tl, freq, 0, 50), ideal, []*Network{Line(tl, freq, 1.5e-3, 50)}, []float64{1.5e-3}, -1)
+	got := cal.Apply(dut)
+	for k := range got.Data {
+		for i := 0; i < 2; i++ {
+			for j := 0; j < 2; j++ {
+				if cmplx.Abs(got.Data[k].Get(i, j)-dut.Data[k].Get(i, j)) > eps {
+					t.Errorf("Ideal corrected S%v%v doesn't match: got %v want %v\n", i+1, j+1, got.Data[k].Get(i, j), dut.Data[k].Get(i, j))
+				}
+			}
+		}
+	}
+}
+
+func TestGaussMarkov(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y []complex128
+		v    [][]complex128
+		res  complex128
+	}{
+		{"uncorrelated", []complex128{1, 1}, []complex128{1, 2}, [][]complex128{{1, 0}, {0, 4}}, 1.2},
+		{"common thru", []complex128{1, 2}, []complex128{1.1, 2}, [][]complex128{{2, 1}, {1, 2}}, 1},
+	}
+	for _, test := range tests {
+		v := cmf(2, 2, opts)
+		for i := range test.v {
+			for j := range test.v[i] {
+				v.Set(i, j, test.v[i][j])
+			}
+		}
+		if got := gaussMarkov(test.x, test.y, v); cmplx.Abs(got-test.res) > eps {
+			t.Errorf("%v estimate doesn't match: got %v want %v\n", test.name, got, test.res)
+		}
+	}
+}
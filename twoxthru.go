@@ -0,0 +1,192 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+// Split2xThru splits a 2x-thru measurement into its left and right fixture
+// halves in the manner of IEEE P370, returned in S so that Cascade(left,
+// right) reproduces the 2x-thru.  The frequencies must be uniformly spaced
+// on a harmonic grid, k*df with or without the DC point, so that the low pass
+// impulse responses are exact.
+//
+// The delay of the 2x-thru is taken from the peak of the S21 impulse response.
+// Reflections arriving at the outer ports within this round trip time come
+// from the halves themselves, so S11 and S22 gated to it give the outer port
+// reflection of each half.  The remaining terms follow in closed form with
+// each half reciprocal and the transmission shared equally between them; the
+// phase of the transmission is unwrapped from DC to pick the square root
+// branch.
+//
+// A 4-port is taken as a differential 2x-thru with ports 1 and 2 the left
+// pair and 3 and 4 the right.  The differential and common modes are split
+// separately, neglecting mode conversion, and the halves are returned single
+// ended with ports 1 and 2 the left pair and 3 and 4 the right.
+func (n *Network) Split2xThru() (left, right *Network) {
+	switch n.NPorts {
+	case 2:
+		return n.splitThru()
+	case 4:
+	default:
+		panic("2x-thru must be a 2-port or a 4-port")
+	}
+
+	mm := n.DeepCopy()
+	mm.Param = S
+	mm.Data = n.S()
+	for k := range mm.Data {
+		mm.Data[k] = mixedMode(mm.Data[k], false)
+	}
+	dl, dr := mm.mode(0).splitThru()
+	cl, cr := mm.mode(2).splitThru()
+
+	left, right = mm.DeepCopy(), mm.DeepCopy()
+	for k := range mm.Data {
+		for _, h := range []struct {
+			net  *Network
+			d, c *mat.CMatrix
+		}{{left, dl.Data[k], cl.Data[k]}, {right, dr.Data[k], cr.Data[k]}} {
+			s := cmf(4, 4, opts)
+			for i := 0; i < 2; i++ {
+				for j := 0; j < 2; j++ {
+					s.Set(i, j, h.d.Get(i, j))
+					s.Set(i+2, j+2, h.c.Get(i, j))
+				}
+			}
+			h.net.Data[k] = mixedMode(s, true)
+		}
+	}
+	left.PortNames, right.PortNames = make([]string, 4), make([]string, 4)
+	if len(n.PortNames) >= 4 {
+		copy(left.PortNames, n.PortNames[:2])
+		copy(right.PortNames[2:], n.PortNames[2:4])
+	}
+
+	return left, right
+}
+
+// splitThru splits a 2-port 2x-thru in S
+func (n *Network) splitThru() (left, right *Network) {
+	f := n.Freq.Freq.Data[:n.Freq.NPts]
+	if !isUniform(f) || (f[0] != 0 && math.Abs(f[1]-2*f[0]) > 1e-6*f[0]) {
+		panic("2x-thru must be on a uniform harmonic frequency grid")
+	}
+
+	t, h := n.ImpulseResponse(1, 0, LowPass, Kaiser)
+	peak := 0
+	for k := range h.Data {
+		if h.Get(k) > h.Get(peak) {
+			peak = k
+		}
+	}
+	tau := t.Get(peak)
+
+	s11, s22 := n.sij(0, 0), n.sij(1, 1)
+	s21, s12 := n.sij(1, 0), n.sij(0, 1)
+	e00, f00 := lowPassTruncate(f, s11, tau), lowPassTruncate(f, s22, tau)
+
+	// with each half reciprocal, e01 = f01 and the cascade gives
+	//   S21 = e01^2/(1 - e11*f11), S11 - e00 = S21*f11, S22 - f00 = S21*e11
+	e11, f11, p := make([]complex128, len(f)), make([]complex128, len(f)), make([]complex128, len(f))
+	for k := range f {
+		st := (s21[k] + s12[k]) / 2
+		e11[k] = (s22[k] - f00[k]) / st
+		f11[k] = (s11[k] - e00[k]) / st
+		p[k] = st * (1 - e11[k]*f11[k])
+	}
+	mag, ang, _, _ := dcExtrapolate(f, p)
+
+	left, right = n.DeepCopy(), n.DeepCopy()
+	left.Param, right.Param = S, S
+	for k := range f {
+		e01 := cmplx.Rect(math.Sqrt(mag[k]), ang[k]/2)
+		left.Data[k] = cmf(2, 2, opts)
+		left.Data[k].Set(0, 0, e00[k])
+		left.Data[k].Set(0, 1, e01)
+		left.Data[k].Set(1, 0, e01)
+		left.Data[k].Set(1, 1, e11[k])
+		right.Data[k] = cmf(2, 2, opts)
+		right.Data[k].Set(0, 0, f11[k])
+		right.Data[k].Set(0, 1, e01)
+		right.Data[k].Set(1, 0, e01)
+		right.Data[k].Set(1, 1, f00[k])
+	}
+	left.PortNames, right.PortNames = make([]string, 2), make([]string, 2)
+	if len(n.PortNames) >= 2 {
+		left.PortNames[0], right.PortNames[1] = n.PortNames[0], n.PortNames[1]
+	}
+
+	return left, right
+}
+
+// lowPassTruncate returns s(f), on a harmonic grid, with its low pass impulse
+// response removed from time tmax on
+func lowPassTruncate(f []float64, s []complex128, tmax float64) []complex128 {
+	fh, sh := f, s
+	if f[0] != 0 {
+		_, _, dcmag, dcang := dcExtrapolate(f, s)
+		fh = append([]float64{0}, f...)
+		sh = append([]complex128{complex(dcmag*math.Cos(dcang), 0)}, s...)
+	}
+
+	t, h := toTime(fh, sh, LowPass, NoWindow)
+	for k := range h {
+		if t[k] >= tmax {
+			h[k] = 0
+		}
+	}
+	out := fromTime(h)
+
+	return out[len(fh)-len(f) : len(fh)]
+}
+
+// mixedMode converts a 4-port S-matrix with ports 1 and 2 the left pair and
+// 3 and 4 the right to mixed mode, ordered differential left and right then
+// common left and right, or back again with inv
+func mixedMode(s *mat.CMatrix, inv bool) *mat.CMatrix {
+	r := complex(1/math.Sqrt2, 0)
+	m := cmf(4, 4, opts)
+	for i := 0; i < 2; i++ {
+		m.Set(i, 2*i, r)
+		m.Set(i, 2*i+1, -r)
+		m.Set(i+2, 2*i, r)
+		m.Set(i+2, 2*i+1, r)
+	}
+	mt := cmf(4, 4, opts)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			mt.Set(i, j, m.Get(j, i))
+		}
+	}
+
+	if inv {
+		return cmmul(cmmul(mt, s), m)
+	}
+	return cmmul(cmmul(m, s), mt)
+}
+
+// mode returns the 2-port of the mixed mode network n starting at port p, 0
+// for differential and 2 for common mode
+func (n *Network) mode(p int) *Network {
+	net := n.DeepCopy()
+	net.SetPorts(2)
+	scale := 2.
+	if p == 2 {
+		scale = 0.5
+	}
+	for i := 0; i < 2; i++ {
+		net.Z0.Set(i, n.Z0.Get(2*i)*complex(scale, 0))
+	}
+	for k := range net.Data {
+		net.Data[k] = cmf(2, 2, opts)
+		for i := 0; i < 2; i++ {
+			for j := 0; j < 2; j++ {
+				net.Data[k].Set(i, j, n.Data[k].Get(p+i, p+j))
+			}
+		}
+	}
+	return net
+}
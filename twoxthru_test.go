@@ -0,0 +1,105 @@
+package gorf
+
+import (
+	"math/cmplx"
+	"testing"
+)
+
+func TestSplit2xThru(t *testing.T) {
+	freq := NewFrequencySweep(0.1, 20, 200, "ghz", Lin)
+	tl := TEMLine{Z0: 50, EpsEff: 2.25, AlphaC: 2}
+	fixture := func(c, l float64) *Network {
+		return Cascade(ShuntImpedance(freq, Capacitor(c, 0, 0, 0), 50), Line(tl, freq, l, 50))
+	}
+	// flip swaps the ports of a reciprocal 2-port
+	flip := func(n *Network) *Network {
+		out := n.DeepCopy()
+		for _, s := range out.Data {
+			s11 := s.Get(0, 0)
+			s.Set(0, 0, s.Get(1, 1))
+			s.Set(1, 1, s11)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name        string
+		left, right *Network
+	}{
+		{"symmetric", fixture(30e-15, 60e-3), flip(fixture(30e-15, 60e-3))},
+		{"asymmetric", fixture(30e-15, 60e-3), flip(fixture(20e-15, 60e-3))},
+	}
+	for _, test := range tests {
+		thru := Cascade(test.left, test.right)
+		left, right := thru.Split2xThru()
+
+		got := Cascade(left, right)
+		flush := thru.DeembedFixture(left, right)
+		for k := range got.Data {
+			for i := 0; i < 2; i++ {
+				for j := 0; j < 2; j++ {
+					if cmplx.Abs(got.Data[k].Get(i, j)-thru.Data[k].Get(i, j)) > 1e-9 {
+						t.Errorf("%v recombined S%v%v doesn't match: got %v want %v\n", test.name, i+1, j+1, got.Data[k].Get(i, j), thru.Data[k].Get(i, j))
+					}
+					if want := complex(float64(i^j), 0); cmplx.Abs(flush.Data[k].Get(i, j)-want) > 1e-9 {
+						t.Errorf("%v de-embedded S%v%v doesn't match: got %v want %v\n", test.name, i+1, j+1, flush.Data[k].Get(i, j), want)
+					}
+				}
+			}
+		}
+
+		for _, h := range []struct {
+			got, want *Network
+		}{{left, test.left}, {right, test.right}} {
+			e := 0.
+			for k := 0; k < 3*freq.NPts/4; k++ {
+				for i := range h.got.Data[k].Data {
+					e = maxf64(e, cmplx.Abs(h.got.Data[k].Data[i]-h.want.Data[k].Data[i]))
+				}
+			}
+			if e > 1e-2 {
+				t.Errorf("%v half doesn't match: got error %v\n", test.name, e)
+			}
+		}
+	}
+
+	// uncoupled pair of the fixtures with ports 1 and 2 outside
+	pair := func(n *Network) *Network {
+		out := n.DeepCopy()
+		out.SetPorts(4)
+		out.Z0.SetReAll(50)
+		for k, s := range n.Data {
+			out.Data[k] = cmf(4, 4, opts)
+			for i := 0; i < 2; i++ {
+				for j := 0; j < 2; j++ {
+					out.Data[k].Set(2*i, 2*j, s.Get(i, j))
+					out.Data[k].Set(2*i+1, 2*j+1, s.Get(i, j))
+				}
+			}
+		}
+		return out
+	}
+	a, b := fixture(30e-15, 60e-3), flip(fixture(20e-15, 60e-3))
+	thru := Cascade(pair(a), pair(b))
+	left, right := thru.Split2xThru()
+	got, l2, r2 := Cascade(left, right), pair(a), pair(b)
+	for k := range got.Data {
+		for i := range got.Data[k].Data {
+			if cmplx.Abs(got.Data[k].Data[i]-thru.Data[k].Data[i]) > 1e-9 {
+				t.Errorf("Differential recombined S doesn't match: got %v want %v\n", got.Data[k].Data[i], thru.Data[k].Data[i])
+			}
+			if k < 3*freq.NPts/4 && (cmplx.Abs(left.Data[k].Data[i]-l2.Data[k].Data[i]) > 1e-2 || cmplx.Abs(right.Data[k].Data[i]-r2.Data[k].Data[i]) > 1e-2) {
+				t.Errorf("Differential half doesn't match: got %v %v want %v %v\n", left.Data[k].Data[i], right.Data[k].Data[i], l2.Data[k].Data[i], r2.Data[k].Data[i])
+			}
+		}
+	}
+
+	// port names are optional
+	for _, n := range []*Network{Cascade(a, b), thru} {
+		n.PortNames = nil
+		left, right := n.Split2xThru()
+		if len(left.PortNames) != n.NPorts || len(right.PortNames) != n.NPorts {
+			t.Errorf("Number of port names doesn't match: got %v %v want %v\n", len(left.PortNames), len(right.PortNames), n.NPorts)
+		}
+	}
+}
@@ -499,7 +499,7 @@ func YtoH(m *mat.CMatrix) *mat.CMatrix {
 	return m
 }
 
-// s = (sqrty0 * z * sqrty0 - I) * (sqrty0 * z * sqrty0 + I)**-1
+// s = (I - sqrtz0 * y * sqrtz0) * (I + sqrtz0 * y * sqrtz0)**-1
 func YtoS(m *mat.CMatrix, z0 *mat.CVector) *mat.CMatrix {
 	var err error
 
@@ -518,7 +518,7 @@ func YtoS(m *mat.CMatrix, z0 *mat.CVector) *mat.CMatrix {
 	if err = goblas.Zgemm(mat.NoTrans, mat.NoTrans, m.Rows, m.Cols, m.Cols, 1, sqz0, m, 0, zwrk); err != nil {
 		panic(err)
 	}
-	if err = goblas.Zgemm(mat.NoTrans, mat.NoTrans, m.Rows, m.Cols, m.Cols, 1, zwrk, sqz0, -1, ztmp); err != nil {
+	if err = goblas.Zgemm(mat.NoTrans, mat.NoTrans, m.Rows, m.Cols, m.Cols, -1, zwrk, sqz0, 1, ztmp); err != nil {
 		panic(err)
 	}
 
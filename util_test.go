@@ -1267,28 +1267,28 @@ var hri2port_yto = [][]complex128{
 	{-0.16173434273916035 + 0.38196834136269786i, -6.382656572608397 + 5.380316586373022i},
 }
 var sri_yto = [][]complex128{
-	{0.9996706300451921 - 0.0006932948472067046i, 0.0011445769021416127 + 0.0011607878524626525i, 0.001068942768334788 - 0.0028442084845683446i, 0.0022160393891765273 + 0.0011674228251476182i, 0.0003314616938696058 + 0.0008981662607645546i, -0.0008682673177250466 + 0.0016921388777036972i},
-	{0.0012597326769750727 + 0.0002827914383497637i, 1.001116638933391 - 0.0004705598521810572i, -0.0006788870619611476 + 0.0012260567669577505i, -0.0005815100323264405 + 0.0009199858383121262i, 0.00045882555392623425 - 0.00012197070356456674i, 0.00011187512175320213 + 0.000357007050650171i},
-	{-0.00023466160984567108 - 0.0011221485487272476i, 0.0013052409233297174 - 0.00041901742796324193i, 0.9947583133444131 + 0.0037492363715425636i, -0.0013269526292792322 - 0.002217949947646791i, 0.0014624294973328705 + 0.0014755634218526215i, 0.004930489677855865 - 0.003345115274062671i},
-	{9.213254628798184e-05 - 0.00041013341371864964i, -0.0014950907784356399 + 0.0006845448123573017i, -0.0021545680670922995 - 0.0009705948103039264i, 1.0004878435891298 + 0.0011269124373110562i, 0.0016995936999227856 - 7.244510992976405e-05i, -0.0006379568262119273 + 0.0004915510081826557i},
-	{0.0009794761012259096 + 0.001800173779029024i, -0.0007917550532868384 - 0.0011130691413567262i, 0.0009490700481819825 + 0.002670397572296479i, -0.0018576415234108912 - 0.0013269889461891005i, 1.000179407986476 + 0.000334025094826218i, 0.00034703052192053274 - 0.0030202395292078243i},
-	{-0.0008490178962090311 + 0.0010852346445165972i, -0.0008479964602857715 + 0.0015925509385091852i, 0.0013962269273515293 - 0.0031213195644546055i, 0.0013484294042964695 + 0.002189855605134114i, 0.0014484499571329745 - 0.0014825906599242322i, 0.9965140925640767 + 0.005819543131564853i},
+	{-0.9996706300451921 + 0.0006932948472067046i, -0.0011445769021416127 - 0.0011607878524626525i, -0.001068942768334788 + 0.0028442084845683446i, -0.0022160393891765273 - 0.0011674228251476182i, -0.0003314616938696058 - 0.0008981662607645546i, 0.0008682673177250466 - 0.0016921388777036972i},
+	{-0.0012597326769750727 - 0.0002827914383497637i, -1.001116638933391 + 0.0004705598521810572i, 0.0006788870619611476 - 0.0012260567669577505i, 0.0005815100323264405 - 0.0009199858383121262i, -0.00045882555392623425 + 0.00012197070356456674i, -0.00011187512175320213 - 0.000357007050650171i},
+	{0.00023466160984567108 + 0.0011221485487272476i, -0.0013052409233297174 + 0.00041901742796324193i, -0.9947583133444131 - 0.0037492363715425636i, 0.0013269526292792322 + 0.002217949947646791i, -0.0014624294973328705 - 0.0014755634218526215i, -0.004930489677855865 + 0.003345115274062671i},
+	{-9.213254628798184e-05 + 0.00041013341371864964i, 0.0014950907784356399 - 0.0006845448123573017i, 0.0021545680670922995 + 0.0009705948103039264i, -1.0004878435891298 - 0.0011269124373110562i, -0.0016995936999227856 + 7.244510992976405e-05i, 0.0006379568262119273 - 0.0004915510081826557i},
+	{-0.0009794761012259096 - 0.001800173779029024i, 0.0007917550532868384 + 0.0011130691413567262i, -0.0009490700481819825 - 0.002670397572296479i, 0.0018576415234108912 + 0.0013269889461891005i, -1.000179407986476 - 0.000334025094826218i, -0.00034703052192053274 + 0.0030202395292078243i},
+	{0.0008490178962090311 - 0.0010852346445165972i, 0.0008479964602857715 - 0.0015925509385091852i, -0.0013962269273515293 + 0.0031213195644546055i, -0.0013484294042964695 - 0.002189855605134114i, -0.0014484499571329745 + 0.0014825906599242322i, -0.9965140925640767 - 0.005819543131564853i},
 }
 var sri2port_yto = [][]complex128{
-	{1.0023259105903242 + 0.002211638860683582i, 0.0012900160608105282 - 0.0007624183250778405i},
-	{0.0017798813484278392 - 0.0008978990102085915i, 1.0036663924368414 + 0.00309839620049962i},
+	{-1.0023259105903242 - 0.002211638860683582i, -0.0012900160608105282 + 0.0007624183250778405i},
+	{-0.0017798813484278392 + 0.0008978990102085915i, -1.0036663924368414 - 0.00309839620049962i},
 }
 var tri_yto = [][]complex128{
-	{-13.916372483107537 - 57.55999387218317i, -120.57627846211307 + 279.28902732558515i, 125.39509703110821 + 227.38401713348628i, 14.24528734055563 + 57.74136496101629i, 120.95267856016052 - 279.23409623126577i, -126.31817213993888 - 226.94628164660512i},
-	{365.62226737737745 + 34.40678957991363i, 139.65514046525297 - 18.232326795688483i, 47.59103910506014 + 161.96389267754407i, -366.0543523951661 - 33.80023428349163i, -138.6871762980821 + 18.468939367895267i, -48.96515410424441 - 162.51198907732845i},
-	{190.81460515903845 + 7.407170795615723i, -0.6150131325525281 + 92.03876186625408i, -70.28962894687005 - 78.79585747725831i, -190.53160521962454 - 7.623040723891016i, 0.6901352764132788 - 92.03046427516041i, 71.15344973938774 + 78.77585170515883i},
-	{-14.48569628 - 57.51643854i, -121.25379333890069 + 279.0535332624634i, 125.71404720562164 + 227.18615643292208i, 14.813883344023868 + 57.69778185391592i, 121.62901990294532 - 279.00041553352133i, -126.63679781853014 - 226.74590601769967i},
-	{365.33899278256956 + 34.38587378141976i, 139.85175665130575 - 18.399303042602565i, 47.22287583565095 + 161.51750838848633i, -365.77073224978784 - 33.78193385068306i, -138.88584081478982 + 18.635970461931713i, -48.59346510038469 - 162.06736064013194i},
-	{191.41466362019116 + 6.80585363115825i, -0.7900963945930141 + 92.5373345021611i, -71.31909195 - 78.93552587i, -191.12946923426736 - 7.022267609435145i, 0.8628325733195865 - 92.53021852770316i, 72.18374529583315 + 78.9147256305905i},
+	{13.916372483107537 + 57.55999387218317i, 120.57627846211307 - 279.28902732558515i, -125.39509703110821 - 227.38401713348628i, 14.24528734055563 + 57.74136496101629i, 120.95267856016052 - 279.23409623126577i, -126.31817213993888 - 226.94628164660512i},
+	{-365.62226737737745 - 34.40678957991363i, -139.65514046525297 + 18.232326795688483i, -47.59103910506014 - 161.96389267754407i, -366.0543523951661 - 33.80023428349163i, -138.6871762980821 + 18.468939367895267i, -48.96515410424441 - 162.51198907732845i},
+	{-190.81460515903845 - 7.407170795615723i, 0.6150131325525281 - 92.03876186625408i, 70.28962894687005 + 78.79585747725831i, -190.53160521962454 - 7.623040723891016i, 0.6901352764132788 - 92.03046427516041i, 71.15344973938774 + 78.77585170515883i},
+	{-14.485696280237919 - 57.516438539785305i, -121.25379333890069 + 279.0535332624634i, 125.71404720562164 + 227.18615643292208i, -14.813883344023868 - 57.69778185391592i, -121.62901990294532 + 279.00041553352133i, 126.63679781853014 + 226.74590601769967i},
+	{365.33899278256956 + 34.38587378141976i, 139.85175665130575 - 18.399303042602565i, 47.22287583565095 + 161.51750838848633i, 365.77073224978784 + 33.78193385068306i, 138.88584081478982 - 18.635970461931713i, 48.59346510038469 + 162.06736064013194i},
+	{191.41466362019116 + 6.80585363115825i, -0.7900963945930141 + 92.5373345021611i, -71.31909194973684 - 78.93552587004525i, 191.12946923426736 + 7.022267609435145i, -0.8628325733195865 + 92.53021852770316i, -72.18374529583315 - 78.9147256305905i},
 }
 var tri2port_yto = [][]complex128{
-	{-449.3390000000015 - 229.67200000000705i, 448.401 + 227.448i},
-	{-448.801 - 228.148i, 447.85900000000146 + 225.93200000000695i},
+	{449.3390000000015 + 229.67200000000705i, 448.4010000000015 + 227.44800000000697i},
+	{-448.80100000000147 - 228.14800000000702i, -447.85900000000146 - 225.93200000000695i},
 }
 var yri_yto = [][]complex128{
 	{-2.9 + 4i, -8.8 + 4.3i, 2.7 - 5.9i, -2.7 + 6.3i, -9.2 + 8.5i, 3.6 - 0.8i},
@@ -0,0 +1,388 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/whipstein/golinalg/mat"
+)
+
+type RationalModel struct {
+	NPorts   int
+	Z0       *mat.CVector
+	Param    RFParam
+	Poles    *mat.CVector   // rad/s, complex poles followed by their conjugate
+	Residues []*mat.CMatrix // residue matrix of each pole
+	D        *mat.Matrix
+	E        *mat.Matrix
+	RMS      *mat.Matrix // RMS fit error of each element
+}
+
+type VectorFitOpts struct {
+	Param      RFParam // parameter to fit, S or Y
+	NPoles     int
+	Iterations int  // pole relocation iterations
+	Relax      bool // use the relaxed non-triviality constraint
+	FitE       bool // fit the proportional term E
+}
+
+func NewVectorFitOpts() VectorFitOpts {
+	return VectorFitOpts{
+		Param:      S,
+		NPoles:     10,
+		Iterations: 10,
+		Relax:      true,
+		FitE:       false,
+	}
+}
+
+// VectorFit fits every element of the network with the common pole rational
+// model
+//
+//	H(s) = sum_m R_m/(s - p_m) + D + s*E
+//
+// by vector fitting.  Starting poles are lightly damped complex pairs spread
+// over the band, which are relocated by repeatedly solving for a weighting
+// function sigma(s) with the same poles and taking its zeros as the new poles.
+// Unstable poles are flipped into the left half plane.  The residues, D and E
+// are real-valued in the time domain: the residues of a conjugate pole pair
+// are themselves conjugate.
+func (n *Network) VectorFit(o VectorFitOpts) *RationalModel {
+	var data []*mat.CMatrix
+
+	switch o.Param {
+	case S:
+		data = n.S()
+	case Y:
+		data = n.Y()
+	default:
+		panic("vector fitting only supports S and Y parameters")
+	}
+
+	// frequencies are normalized to the top of the band for conditioning
+	ws := n.Freq.W.Get(n.Freq.NPts - 1)
+	s := make([]complex128, n.Freq.NPts)
+	for k := range s {
+		s[k] = complex(0, n.Freq.W.Get(k)/ws)
+	}
+	f := make([][]complex128, 0)
+	for i := 0; i < n.NPorts; i++ {
+		for j := 0; j < n.NPorts; j++ {
+			fc := make([]complex128, len(s))
+			for k := range s {
+				fc[k] = data[k].Get(i, j)
+			}
+			f = append(f, fc)
+		}
+	}
+
+	poles := startingPoles(imag(s[0]), imag(s[len(s)-1]), o.NPoles)
+	for it := 0; it < o.Iterations; it++ {
+		poles = relocatePoles(s, f, poles, o.Relax, o.FitE)
+	}
+
+	m := &RationalModel{
+		NPorts: n.NPorts,
+		Z0:     n.Z0.DeepCopy(),
+		Param:  o.Param,
+		Poles:  cvf(len(poles)),
+		D:      mf(n.NPorts, n.NPorts, opts),
+		E:      mf(n.NPorts, n.NPorts, opts),
+		RMS:    mf(n.NPorts, n.NPorts, opts),
+	}
+	for p := range poles {
+		m.Poles.Set(p, poles[p]*complex(ws, 0))
+		m.Residues = append(m.Residues, cmf(n.NPorts, n.NPorts, opts))
+	}
+
+	for c, fc := range f {
+		i, j := c/n.NPorts, c%n.NPorts
+		r, d, e := fitResidues(s, fc, poles, o.FitE)
+		for p := range poles {
+			m.Residues[p].Set(i, j, r[p]*complex(ws, 0))
+		}
+		m.D.Set(i, j, d)
+		m.E.Set(i, j, e/ws)
+	}
+
+	for c, fc := range f {
+		i, j := c/n.NPorts, c%n.NPorts
+		sum := 0.
+		for k := range s {
+			sum += math.Pow(cmplx.Abs(m.eval(s[k]*complex(ws, 0)).Get(i, j)-fc[k]), 2)
+		}
+		m.RMS.Set(i, j, math.Sqrt(sum/float64(len(s))))
+	}
+
+	return m
+}
+
+// Evaluate returns the model evaluated on freq as a network in the fitted
+// parameter
+func (m *RationalModel) Evaluate(freq *Frequency) *Network {
+	net := NewNetwork()
+	net.SetPorts(m.NPorts)
+	net.Z0 = m.Z0.DeepCopy()
+	net.Param = m.Param
+	net.Freq = freq.DeepCopy()
+
+	for k := 0; k < freq.NPts; k++ {
+		net.Data = append(net.Data, m.eval(complex(0, freq.W.Get(k))))
+	}
+
+	return net
+}
+
+// eval returns the model at complex frequency s (rad/s)
+func (m *RationalModel) eval(s complex128) *mat.CMatrix {
+	h := cmf(m.NPorts, m.NPorts, opts)
+	for i := 0; i < m.NPorts; i++ {
+		for j := 0; j < m.NPorts; j++ {
+			val := complex(m.D.Get(i, j), 0) + s*complex(m.E.Get(i, j), 0)
+			for p := 0; p < m.Poles.Size; p++ {
+				val += m.Residues[p].Get(i, j) / (s - m.Poles.Get(p))
+			}
+			h.Set(i, j, val)
+		}
+	}
+	return h
+}
+
+// startingPoles returns npoles lightly damped poles spread linearly between
+// wmin and wmax, as complex pairs plus one real pole if npoles is odd
+func startingPoles(wmin, wmax float64, npoles int) []complex128 {
+	poles := make([]complex128, 0)
+	npairs := npoles / 2
+	if npoles%2 == 1 {
+		poles = append(poles, complex(-(wmin+wmax)/2, 0))
+	}
+	for k := 0; k < npairs; k++ {
+		b := wmin + (wmax-wmin)*float64(k)/math.Max(float64(npairs-1), 1)
+		if b == 0 {
+			b = wmax / 100
+		}
+		poles = append(poles, complex(-b/100, b), complex(-b/100, -b))
+	}
+	return poles
+}
+
+// basis returns the real-coefficient partial fraction basis at s: 1/(s-p) for
+// a real pole and 1/(s-p) + 1/(s-p*), j/(s-p) - j/(s-p*) for a pair
+func basis(s complex128, poles []complex128) []complex128 {
+	phi := make([]complex128, len(poles))
+	for m := 0; m < len(poles); m++ {
+		if imag(poles[m]) == 0 {
+			phi[m] = 1 / (s - poles[m])
+		} else {
+			a, b := 1/(s-poles[m]), 1/(s-cmplx.Conj(poles[m]))
+			phi[m] = a + b
+			phi[m+1] = 1i*a - 1i*b
+			m++
+		}
+	}
+	return phi
+}
+
+// relocatePoles performs one pole identification step and returns the zeros
+// of the fitted sigma function as the new poles.  As in fast vector fitting,
+// the system of each element is reduced by QR to the rows involving only the
+// common sigma coefficients, so the final least squares problem grows with
+// the number of elements rather than with its square.
+func relocatePoles(s []complex128, f [][]complex128, poles []complex128, relax, fitE bool) []complex128 {
+	npoles, nc, nk := len(poles), len(f), len(s)
+	nd := 1
+	if fitE {
+		nd = 2
+	}
+	nsig := npoles
+	if relax {
+		nsig++
+	}
+	nres := npoles + nd
+	nrows := nc * nsig
+	if relax {
+		nrows++
+	}
+
+	a := mf(nrows, nsig, opts)
+	b := vf(nrows)
+	norm := 0.
+	for c := range f {
+		// [residue terms | sigma terms | rhs] for this element
+		ac := mf(2*nk, nres+nsig+1, opts)
+		for k := range s {
+			phi := basis(s[k], poles)
+			fk := f[c][k]
+			norm += math.Pow(cmplx.Abs(fk), 2)
+
+			set := func(col int, val complex128) {
+				ac.Set(2*k, col, real(val))
+				ac.Set(2*k+1, col, imag(val))
+			}
+			for m := range phi {
+				set(m, phi[m])
+				set(nres+m, -fk*phi[m])
+			}
+			set(npoles, 1)
+			if fitE {
+				set(npoles+1, s[k])
+			}
+			if relax {
+				set(nres+npoles, -fk)
+			} else {
+				set(nres+nsig, fk)
+			}
+		}
+		for j := 0; j < nres; j++ {
+			scale := 0.
+			for i := 0; i < ac.Rows; i++ {
+				scale += ac.Get(i, j) * ac.Get(i, j)
+			}
+			if scale = math.Sqrt(scale); scale > 0 {
+				for i := 0; i < ac.Rows; i++ {
+					ac.Set(i, j, ac.Get(i, j)/scale)
+				}
+			}
+		}
+
+		r := qrR(ac)
+		for i := 0; i < nsig && nres+i < r.Rows; i++ {
+			for j := 0; j < nsig; j++ {
+				a.Set(c*nsig+i, j, r.Get(nres+i, nres+j))
+			}
+			b.Set(c*nsig+i, r.Get(nres+i, nres+nsig))
+		}
+	}
+
+	if relax {
+		// sum of Re(sigma) over the band fixed to nk, weighted like the data
+		scale := math.Sqrt(norm) / float64(nk)
+		row := nrows - 1
+		for k := range s {
+			phi := basis(s[k], poles)
+			for m := range phi {
+				a.Set(row, m, a.Get(row, m)+scale*real(phi[m]))
+			}
+		}
+		a.Set(row, npoles, scale*float64(nk))
+		b.Set(row, scale*float64(nk))
+	}
+
+	x := scaledLstsq(a, b)
+
+	dsig := 1.
+	if relax {
+		dsig = x.Get(nsig - 1)
+		if math.Abs(dsig) < 1e-8 {
+			dsig = math.Copysign(1e-8, dsig)
+		}
+	}
+
+	// zeros of sigma are the eigenvalues of A - b*c^T/d
+	h := mf(npoles, npoles, opts)
+	bv := make([]float64, npoles)
+	for m := 0; m < npoles; m++ {
+		if imag(poles[m]) == 0 {
+			h.Set(m, m, real(poles[m]))
+			bv[m] = 1
+		} else {
+			h.Set(m, m, real(poles[m]))
+			h.Set(m, m+1, imag(poles[m]))
+			h.Set(m+1, m, -imag(poles[m]))
+			h.Set(m+1, m+1, real(poles[m]))
+			bv[m] = 2
+			m++
+		}
+	}
+	for i := 0; i < npoles; i++ {
+		for j := 0; j < npoles; j++ {
+			h.Set(i, j, h.Get(i, j)-bv[i]*x.Get(j)/dsig)
+		}
+	}
+
+	newPoles := make([]complex128, 0)
+	for _, val := range eig(h) {
+		if real(val) > 0 {
+			val = complex(-real(val), imag(val))
+		}
+		if imag(val) > 0 {
+			newPoles = append(newPoles, val, cmplx.Conj(val))
+		} else if imag(val) == 0 {
+			newPoles = append(newPoles, val)
+		}
+	}
+
+	return newPoles
+}
+
+// fitResidues returns the residues of f on the fixed poles and the constant
+// and proportional terms
+func fitResidues(s, f, poles []complex128, fitE bool) ([]complex128, float64, float64) {
+	npoles := len(poles)
+	ncols := npoles + 1
+	if fitE {
+		ncols++
+	}
+
+	a := mf(2*len(s), ncols, opts)
+	b := vf(2 * len(s))
+	for k := range s {
+		phi := basis(s[k], poles)
+		set := func(col int, val complex128) {
+			a.Set(2*k, col, real(val))
+			a.Set(2*k+1, col, imag(val))
+		}
+		for m := range phi {
+			set(m, phi[m])
+		}
+		set(npoles, 1)
+		if fitE {
+			set(npoles+1, s[k])
+		}
+		b.Set(2*k, real(f[k]))
+		b.Set(2*k+1, imag(f[k]))
+	}
+
+	x := scaledLstsq(a, b)
+
+	r := make([]complex128, npoles)
+	for m := 0; m < npoles; m++ {
+		if imag(poles[m]) == 0 {
+			r[m] = complex(x.Get(m), 0)
+		} else {
+			r[m] = complex(x.Get(m), x.Get(m+1))
+			r[m+1] = cmplx.Conj(r[m])
+			m++
+		}
+	}
+
+	e := 0.
+	if fitE {
+		e = x.Get(npoles + 1)
+	}
+	return r, x.Get(npoles), e
+}
+
+// scaledLstsq solves the least squares problem with each column of a scaled
+// to unit norm
+func scaledLstsq(a *mat.Matrix, b *mat.Vector) *mat.Vector {
+	scale := make([]float64, a.Cols)
+	for j := 0; j < a.Cols; j++ {
+		for i := 0; i < a.Rows; i++ {
+			scale[j] += a.Get(i, j) * a.Get(i, j)
+		}
+		scale[j] = math.Sqrt(scale[j])
+		if scale[j] == 0 {
+			scale[j] = 1
+		}
+		for i := 0; i < a.Rows; i++ {
+			a.Set(i, j, a.Get(i, j)/scale[j])
+		}
+	}
+
+	x := lstsq(a, b)
+	for j := range scale {
+		x.Set(j, x.Get(j)/scale[j])
+	}
+	return x
+}
@@ -0,0 +1,97 @@
+package gorf
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+	"testing"
+)
+
+// testModel returns a 2-port rational model with two pole pairs and a real
+// pole
+func testModel() *RationalModel {
+	poles := []complex128{complex(-0.3e9, 2*math.Pi*3e9), complex(-0.3e9, -2*math.Pi*3e9), complex(-1e9, 2*math.Pi*7e9), complex(-1e9, -2*math.Pi*7e9), -2e9}
+	res := [][][]complex128{
+		{{1e8 + 2e8i, 5e8 - 1e8i}, {5e8 - 1e8i, 3e8}},
+		{{1e8 - 2e8i, 5e8 + 1e8i}, {5e8 + 1e8i, 3e8}},
+		{{-4e8 + 1e8i, 2e8}, {2e8, 1e8 + 1e8i}},
+		{{-4e8 - 1e8i, 2e8}, {2e8, 1e8 - 1e8i}},
+		{{2e8, 1e8}, {1e8, -3e8}},
+	}
+
+	m := &RationalModel{NPorts: 2, Z0: cvf(2), Param: S, Poles: cvf(0), D: mf(2, 2, opts), E: mf(2, 2, opts), RMS: mf(2, 2, opts)}
+	m.Z0.SetReAll(50)
+	for p := range poles {
+		m.Poles.Append(poles[p])
+		r := cmf(2, 2, opts)
+		for i := 0; i < 2; i++ {
+			for j := 0; j < 2; j++ {
+				r.Set(i, j, res[p][i][j])
+			}
+		}
+		m.Residues = append(m.Residues, r)
+	}
+	m.D.Set(0, 0, 0.1)
+	m.D.Set(1, 1, -0.2)
+	return m
+}
+
+func TestVectorFit(t *testing.T) {
+	ref := testModel()
+	net := ref.Evaluate(delayNetwork(0).Freq)
+
+	o := NewVectorFitOpts()
+	o.NPoles = 5
+	m := net.VectorFit(o)
+
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			if m.RMS.Get(i, j) > 1e-8 {
+				t.Errorf("RMS error too large for S%v%v: got %v\n", i+1, j+1, m.RMS.Get(i, j))
+			}
+		}
+	}
+
+	got, want := append([]complex128{}, m.Poles.Data...), append([]complex128{}, ref.Poles.Data...)
+	for _, p := range [][]complex128{got, want} {
+		sort.Slice(p, func(a, b int) bool {
+			if real(p[a]) != real(p[b]) {
+				return real(p[a]) < real(p[b])
+			}
+			return imag(p[a]) < imag(p[b])
+		})
+	}
+	for k := range want {
+		if cmplx.Abs(got[k]-want[k]) > 1e-6*cmplx.Abs(want[k]) {
+			t.Errorf("Pole doesn't match: got %v want %v\n", got[k], want[k])
+		}
+	}
+
+	fit := m.Evaluate(net.Freq)
+	for k := range net.Data {
+		for i := 0; i < 2; i++ {
+			for j := 0; j < 2; j++ {
+				if cmplx.Abs(fit.Data[k].Get(i, j)-net.Data[k].Get(i, j)) > 1e-8 {
+					t.Errorf("Evaluated model doesn't match: got %v want %v\n", fit.Data[k].Get(i, j), net.Data[k].Get(i, j))
+				}
+			}
+		}
+	}
+}
+
+func TestVectorFitMeasured(t *testing.T) {
+	net := delayNetwork(0.2e-9)
+
+	o := NewVectorFitOpts()
+	o.NPoles = 16
+	o.Relax = false
+	m := net.VectorFit(o)
+	if m.RMS.Get(1, 0) > 1e-9 {
+		t.Errorf("RMS error too large: got %v\n", m.RMS.Get(1, 0))
+	}
+	for _, p := range m.Poles.Data {
+		if real(p) >= 0 {
+			t.Errorf("Unstable pole: got %v\n", p)
+		}
+	}
+}